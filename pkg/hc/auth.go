@@ -0,0 +1,260 @@
+package hc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Authenticator adds the credentials to the requests, it's called for every attempt so that expired credentials can
+// be renewed. Configure it with Options.WithAuth or Request.WithAuth.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthenticatorFunc allows to use a function as an Authenticator
+type AuthenticatorFunc func(req *http.Request) error
+
+func (f AuthenticatorFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+// BasicAuth authenticates the requests with a username and a password
+func BasicAuth(username, password string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		req.SetBasicAuth(username, password)
+		return nil
+	})
+}
+
+// ApiKeyHeader sends the api key in a header, eg. X-Api-Key
+func ApiKeyHeader(name, key string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		req.Header.Set(name, key)
+		return nil
+	})
+}
+
+// ApiKeyQuery sends the api key as a query parameter, eg. api_key
+func ApiKeyQuery(name, key string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		q := req.URL.Query()
+		q.Set(name, key)
+		req.URL.RawQuery = q.Encode()
+		return nil
+	})
+}
+
+// expiryDelta is how long before their expiry the tokens are considered expired, to account for clock skews and
+// for the time the request takes to reach the server
+const expiryDelta = 10 * time.Second
+
+// tokenTimeout limits the token fetches, which don't follow the cancellation of the requests
+const tokenTimeout = 30 * time.Second
+
+// ErrEmptyToken is returned when a token source returns a token without an access token
+var ErrEmptyToken = errors.New("hc: empty token")
+
+// Token is an access token with its expiry, a zero expiry means that the token never expires
+type Token struct {
+	AccessToken string
+	TokenType   string
+	Expiry      time.Time
+}
+
+// Valid tells if the token is set and not about to expire
+func (t *Token) Valid() bool {
+	return t != nil && t.AccessToken != "" && (t.Expiry.IsZero() || time.Now().Add(expiryDelta).Before(t.Expiry))
+}
+
+// Type returns the type of the token for the Authorization header, Bearer by default
+func (t *Token) Type() string {
+	if t.TokenType == "" || strings.EqualFold(t.TokenType, "bearer") {
+		return "Bearer"
+	}
+
+	return t.TokenType
+}
+
+// TokenSource returns a token, eg. fetching it from an identity provider
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// TokenSourceFunc allows to use a function as a TokenSource
+type TokenSourceFunc func(ctx context.Context) (*Token, error)
+
+func (f TokenSourceFunc) Token(ctx context.Context) (*Token, error) {
+	return f(ctx)
+}
+
+// StaticToken always returns the same token, which never expires
+func StaticToken(v string) TokenSource {
+	return TokenSourceFunc(func(context.Context) (*Token, error) {
+		return &Token{AccessToken: v}, nil
+	})
+}
+
+// BearerAuth authenticates the requests with the tokens of the source, a token is cached until shortly before it
+// expires and concurrent requests share the same fetch
+func BearerAuth(source TokenSource) Authenticator {
+	return &bearerAuth{source: source}
+}
+
+type bearerAuth struct {
+	source TokenSource
+
+	mu    sync.Mutex
+	token *Token
+	fetch *tokenFetch
+}
+
+// tokenFetch is a token request in flight, the callers which need a token meanwhile wait for it
+type tokenFetch struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+func (a *bearerAuth) Authenticate(req *http.Request) error {
+	t, err := a.get(req.Context())
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", authorization(t))
+	return nil
+}
+
+// get returns the cached token, fetching a new one if it's missing or expired
+func (a *bearerAuth) get(ctx context.Context) (*Token, error) {
+	a.mu.Lock()
+	if a.token.Valid() {
+		t := a.token
+		a.mu.Unlock()
+		return t, nil
+	}
+
+	f := a.fetch
+	if f != nil {
+		a.mu.Unlock()
+
+		select {
+		case <-f.done:
+			return f.token, f.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	f = &tokenFetch{done: make(chan struct{})}
+	a.fetch = f
+	a.mu.Unlock()
+
+	go a.run(f, ctx)
+
+	select {
+	case <-f.done:
+		return f.token, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// run fetches the token shared by the waiting callers, on a context which keeps the values of the caller that started
+// it but not its cancellation, so that a short deadline of one request doesn't fail the others
+func (a *bearerAuth) run(f *tokenFetch, ctx context.Context) {
+	ctx, cancel := context.WithTimeout(detachedContext{ctx}, tokenTimeout)
+	defer cancel()
+
+	f.token, f.err = a.source.Token(ctx)
+	if f.err == nil && (f.token == nil || f.token.AccessToken == "") {
+		f.token, f.err = nil, ErrEmptyToken
+	}
+
+	a.mu.Lock()
+	if f.err == nil {
+		a.token = f.token
+	}
+	a.fetch = nil
+	a.mu.Unlock()
+	close(f.done)
+}
+
+// detachedContext keeps the values of its parent without its deadline and cancellation
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+// invalidate discards the cached token if it's the one sent with the header, so that a token renewed meanwhile by
+// another request is kept
+func (a *bearerAuth) invalidate(header string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != nil && authorization(a.token) == header {
+		a.token = nil
+	}
+}
+
+func authorization(t *Token) string {
+	return t.Type() + " " + t.AccessToken
+}
+
+// reauthenticator is implemented by the authenticators which can renew the credentials rejected by the server
+type reauthenticator interface {
+	Reauthenticate(req *http.Request) error
+}
+
+// authMiddleware authenticates every attempt that goes through it, an attempt rejected with 401 is sent once more
+// with fresh credentials when the authenticator can renew them and the body can be sent again
+func authMiddleware(a Authenticator) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			if err := a.Authenticate(req); err != nil {
+				return nil, err
+			}
+
+			res, err := next.Do(req)
+			re, ok := a.(reauthenticator)
+			if !ok || err != nil || res.StatusCode != http.StatusUnauthorized {
+				return res, err
+			}
+			// streamed bodies are never buffered, so they can't be replayed
+			if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+				return res, nil
+			}
+
+			discard(res)
+
+			retry := req.Clone(req.Context())
+			if err := re.Reauthenticate(retry); err != nil {
+				return nil, err
+			}
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				retry.Body = body
+			}
+
+			return next.Do(retry)
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package hc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jacoz/go-http-client/pkg/hc/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuthenticators(t *testing.T) {
+	var tests = []struct {
+		name       string
+		input      Authenticator
+		wantHeader http.Header
+		wantUrl    string
+	}{
+		{
+			"basic",
+			BasicAuth("foo", "bar"),
+			http.Header{"Authorization": {"Basic Zm9vOmJhcg=="}},
+			"https://example.com/foo?page=1",
+		},
+		{
+			"api key header",
+			ApiKeyHeader("x-api-key", "secret"),
+			http.Header{"X-Api-Key": {"secret"}},
+			"https://example.com/foo?page=1",
+		},
+		{
+			"api key query",
+			ApiKeyQuery("api_key", "secret"),
+			http.Header{},
+			"https://example.com/foo?api_key=secret&page=1",
+		},
+		{
+			"static token",
+			BearerAuth(StaticToken("secret")),
+			http.Header{"Authorization": {"Bearer secret"}},
+			"https://example.com/foo?page=1",
+		},
+		{
+			"token type",
+			BearerAuth(TokenSourceFunc(func(context.Context) (*Token, error) {
+				return &Token{AccessToken: "secret", TokenType: "MAC"}, nil
+			})),
+			http.Header{"Authorization": {"MAC secret"}},
+			"https://example.com/foo?page=1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "https://example.com/foo?page=1", nil)
+
+			assert.Nil(t, tt.input.Authenticate(req))
+			assert.Equal(t, tt.wantHeader, req.Header)
+			assert.Equal(t, tt.wantUrl, req.URL.String())
+		})
+	}
+}
+
+func TestToken_Valid(t *testing.T) {
+	var tests = []struct {
+		name  string
+		input *Token
+		want  bool
+	}{
+		{"nil", nil, false},
+		{"empty", &Token{}, false},
+		{"without expiry", &Token{AccessToken: "foo"}, true},
+		{"expired", &Token{AccessToken: "foo", Expiry: time.Now().Add(-time.Minute)}, false},
+		{"about to expire", &Token{AccessToken: "foo", Expiry: time.Now().Add(time.Second)}, false},
+		{"valid", &Token{AccessToken: "foo", Expiry: time.Now().Add(time.Minute)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.input.Valid())
+		})
+	}
+}
+
+func TestBearerAuth_Cache(t *testing.T) {
+	var calls int32
+	auth := BearerAuth(TokenSourceFunc(func(context.Context) (*Token, error) {
+		n := atomic.AddInt32(&calls, 1)
+
+		expiry := time.Now().Add(time.Hour)
+		if n == 1 {
+			expiry = time.Now().Add(expiryDelta)
+		}
+		return &Token{AccessToken: fmt.Sprintf("token-%d", n), Expiry: expiry}, nil
+	}))
+
+	for _, want := range []string{"Bearer token-1", "Bearer token-2", "Bearer token-2"} {
+		req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+		assert.Nil(t, auth.Authenticate(req))
+		assert.Equal(t, want, req.Header.Get("Authorization"))
+	}
+
+	// the first token is about to expire, so it's fetched once more and then cached
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestBearerAuth_SingleFlight(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	auth := BearerAuth(TokenSourceFunc(func(context.Context) (*Token, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return &Token{AccessToken: "foo"}, nil
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+			assert.Nil(t, auth.Authenticate(req))
+			assert.Equal(t, "Bearer foo", req.Header.Get("Authorization"))
+		}()
+	}
+
+	// a waiting request gives up when its context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com", nil)
+	for atomic.LoadInt32(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, context.Canceled, auth.Authenticate(req))
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestBearerAuth_DetachedFetch(t *testing.T) {
+	type key struct{}
+
+	auth := BearerAuth(TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+		time.Sleep(50 * time.Millisecond)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return &Token{AccessToken: ctx.Value(key{}).(string)}, nil
+	}))
+
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), key{}, "foo"), 10*time.Millisecond)
+	defer cancel()
+	short, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com", nil)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Equal(t, context.DeadlineExceeded, auth.Authenticate(short))
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	// the deadline of the request which started the fetch doesn't fail the others
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	assert.Nil(t, auth.Authenticate(req))
+	assert.Equal(t, "Bearer foo", req.Header.Get("Authorization"))
+
+	wg.Wait()
+}
+
+func TestBearerAuth_Errors(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+
+	err := BearerAuth(TokenSourceFunc(func(context.Context) (*Token, error) {
+		return nil, errors.New("unauthorized client")
+	})).Authenticate(req)
+	assert.EqualError(t, err, "unauthorized client")
+
+	err = BearerAuth(StaticToken("")).Authenticate(req)
+	assert.Equal(t, ErrEmptyToken, err)
+	assert.Empty(t, req.Header.Get("Authorization"))
+}
+
+func TestDefaultClient_Auth(t *testing.T) {
+	var tests = []struct {
+		name    string
+		options *Options
+		request *Request
+		want    string
+	}{
+		{
+			"client",
+			Opts().WithAuth(BasicAuth("foo", "bar")),
+			Req(),
+			"Basic Zm9vOmJhcg==",
+		},
+		{
+			"request",
+			Opts().WithAuth(BasicAuth("foo", "bar")),
+			Req().WithAuth(BearerAuth(StaticToken("secret"))),
+			"Bearer secret",
+		},
+		{
+			"visible to the middlewares",
+			Opts().WithAuth(BasicAuth("foo", "bar")).Use(func(next Doer) Doer {
+				return DoerFunc(func(req *http.Request) (*http.Response, error) {
+					req.Header.Set("X-Signature", req.Header.Get("Authorization"))
+					return next.Do(req)
+				})
+			}),
+			Req(),
+			"Basic Zm9vOmJhcg==",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goHttpClientMock := mocks.NewGoHttpClient(t)
+			goHttpClientMock.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+				signature := req.Header.Get("X-Signature")
+				return req.Header.Get("Authorization") == tt.want && (signature == "" || signature == tt.want)
+			})).Return(&http.Response{StatusCode: 200}, nil).Once()
+
+			c := newClient(tt.options.BaseUrl("https://example.com"))
+			c.client = goHttpClientMock
+
+			_, err := c.Get(context.Background(), "/foo", nil, tt.request)
+			assert.Nil(t, err)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package hc
+
+import (
+	"io"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const formContentType = "application/x-www-form-urlencoded"
+
+// contentTyper is implemented by the bodies that know their own Content-Type, eg. forms and multipart bodies
+type contentTyper interface {
+	ContentType() string
+}
+
+// unwrapper is implemented by the bodies wrapping a reader that http.NewRequest knows how to size and rewind
+type unwrapper interface {
+	unwrap() io.Reader
+}
+
+// formBody is an url encoded form
+type formBody struct {
+	*strings.Reader
+}
+
+// Form encodes the values as an application/x-www-form-urlencoded body, the Content-Type header is set automatically
+func Form(v url.Values) io.Reader {
+	return formBody{strings.NewReader(v.Encode())}
+}
+
+func (formBody) ContentType() string {
+	return formContentType
+}
+
+func (b formBody) unwrap() io.Reader {
+	return b.Reader
+}
+
+// MultipartBody is a multipart/form-data body, use Multipart to create it. The parts are streamed through a pipe as
+// the body is read, so files are never loaded in memory as a whole.
+type MultipartBody struct {
+	parts []multipartPart
+
+	once   sync.Once
+	reader *io.PipeReader
+	writer *io.PipeWriter
+	mw     *multipart.Writer
+}
+
+type multipartPart struct {
+	field    string
+	value    string
+	filename string
+	file     io.Reader
+	path     string
+}
+
+// Multipart creates a multipart/form-data body, the Content-Type header with the boundary is set automatically
+func Multipart() *MultipartBody {
+	pr, pw := io.Pipe()
+
+	return &MultipartBody{
+		reader: pr,
+		writer: pw,
+		mw:     multipart.NewWriter(pw),
+	}
+}
+
+// Field adds a form field
+func (b *MultipartBody) Field(k, v string) *MultipartBody {
+	b.parts = append(b.parts, multipartPart{field: k, value: v})
+	return b
+}
+
+// File adds a file read from r, which is not closed
+func (b *MultipartBody) File(field, filename string, r io.Reader) *MultipartBody {
+	b.parts = append(b.parts, multipartPart{field: field, filename: filename, file: r})
+	return b
+}
+
+// FileFromPath adds a file from the disk, it's opened only when the body is sent
+func (b *MultipartBody) FileFromPath(field, path string) *MultipartBody {
+	b.parts = append(b.parts, multipartPart{field: field, filename: filepath.Base(path), path: path})
+	return b
+}
+
+// ContentType returns multipart/form-data with the boundary of the body
+func (b *MultipartBody) ContentType() string {
+	return b.mw.FormDataContentType()
+}
+
+// Read starts writing the parts in the background the first time it's called
+func (b *MultipartBody) Read(p []byte) (int, error) {
+	b.once.Do(func() {
+		go func() {
+			b.writer.CloseWithError(b.write())
+		}()
+	})
+
+	return b.reader.Read(p)
+}
+
+// Close stops writing the parts, it's called by the transport once the request is sent
+func (b *MultipartBody) Close() error {
+	return b.reader.Close()
+}
+
+func (b *MultipartBody) write() error {
+	for _, p := range b.parts {
+		if err := b.writePart(p); err != nil {
+			return err
+		}
+	}
+
+	return b.mw.Close()
+}
+
+func (b *MultipartBody) writePart(p multipartPart) error {
+	if p.file == nil && p.path == "" {
+		return b.mw.WriteField(p.field, p.value)
+	}
+
+	w, err := b.mw.CreateFormFile(p.field, p.filename)
+	if err != nil {
+		return err
+	}
+
+	file := p.file
+	if p.path != "" {
+		f, err := os.Open(p.path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		file = f
+	}
+
+	_, err = io.Copy(w, file)
+	return err
+}
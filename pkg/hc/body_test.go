@@ -0,0 +1,126 @@
+package hc
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForm(t *testing.T) {
+	body := Form(url.Values{"foo": {"bar", "baz"}, "a b": {"c&d"}})
+
+	b, err := io.ReadAll(body)
+	assert.Nil(t, err)
+	assert.Equal(t, "a+b=c%26d&foo=bar&foo=baz", string(b))
+	assert.Equal(t, formContentType, body.(contentTyper).ContentType())
+}
+
+func TestMultipart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.csv")
+	assert.Nil(t, os.WriteFile(path, []byte("a,b"), 0o600))
+
+	body := Multipart().
+		Field("name", "foo").
+		File("avatar", "avatar.png", strings.NewReader("png")).
+		FileFromPath("report", path)
+
+	b, err := io.ReadAll(body)
+	assert.Nil(t, err)
+
+	req := &http.Request{
+		Method: http.MethodPost,
+		Header: http.Header{"Content-Type": {body.ContentType()}},
+		Body:   io.NopCloser(strings.NewReader(string(b))),
+	}
+	assert.Nil(t, req.ParseMultipartForm(1<<20))
+
+	assert.Equal(t, []string{"foo"}, req.MultipartForm.Value["name"])
+	assert.Equal(t, "avatar.png", req.MultipartForm.File["avatar"][0].Filename)
+	assert.Equal(t, "report.csv", req.MultipartForm.File["report"][0].Filename)
+}
+
+func TestMultipart_MissingFile(t *testing.T) {
+	body := Multipart().FileFromPath("report", filepath.Join(t.TempDir(), "missing.csv"))
+
+	_, err := io.ReadAll(body)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDefaultClient_Forms(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil && err != http.ErrNotMultipart {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		for k := range r.PostForm {
+			w.Write([]byte(k + "=" + r.PostForm.Get(k) + ";"))
+		}
+		if r.MultipartForm != nil {
+			for k, files := range r.MultipartForm.File {
+				f, _ := files[0].Open()
+				b, _ := io.ReadAll(f)
+				w.Write([]byte(k + ":" + files[0].Filename + "=" + string(b) + ";"))
+			}
+		}
+	}))
+	defer server.Close()
+
+	var tests = []struct {
+		name    string
+		options *Options
+		call    func(c *defaultClient) (*Response, error)
+		want    string
+	}{
+		{
+			"form",
+			Opts(),
+			func(c *defaultClient) (*Response, error) {
+				return c.Post(context.Background(), "/", Form(url.Values{"foo": {"bar"}}))
+			},
+			"foo=bar;",
+		},
+		{
+			"form in the request",
+			Opts().WithDefaultHeader("Content-Type", jsonContentType),
+			func(c *defaultClient) (*Response, error) {
+				return c.Put(context.Background(), "/", nil, Req().Body(Form(url.Values{"foo": {"bar"}})))
+			},
+			"foo=bar;",
+		},
+		{
+			"multipart",
+			Opts(),
+			func(c *defaultClient) (*Response, error) {
+				return c.Post(context.Background(), "/", Multipart().File("file", "foo.txt", strings.NewReader("bar")))
+			},
+			"file:foo.txt=bar;",
+		},
+		{
+			"multipart with retries",
+			Opts().WithRetry(Retry()),
+			func(c *defaultClient) (*Response, error) {
+				return c.Post(context.Background(), "/", Multipart().Field("foo", "bar"))
+			},
+			"foo=bar;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.call(newClient(tt.options.BaseUrl(server.URL)))
+
+			assert.Nil(t, err)
+			assert.Equal(t, 200, got.StatusCode())
+			assert.Equal(t, tt.want, string(got.Debug()))
+		})
+	}
+}
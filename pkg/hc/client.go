@@ -5,19 +5,36 @@ import (
 	"io"
 )
 
+// Client is the interface of the http client, it's expressed only in exported types so that it can be implemented
+// by decorators and test doubles
 type Client interface {
 	// Get performs a GET request
-	Get(ctx context.Context, endpoint string, q *Q, r ...*request) (*response, error)
+	Get(ctx context.Context, endpoint string, q *Q, r ...*Request) (*Response, error)
 
 	// Post performs a POST request
-	Post(ctx context.Context, endpoint string, body io.Reader, r ...*request) (*response, error)
+	Post(ctx context.Context, endpoint string, body io.Reader, r ...*Request) (*Response, error)
 
 	// Patch performs a PATCH request
-	Patch(ctx context.Context, endpoint string, body io.Reader, r ...*request) (*response, error)
+	Patch(ctx context.Context, endpoint string, body io.Reader, r ...*Request) (*Response, error)
 
 	// Put performs a PUT request
-	Put(ctx context.Context, endpoint string, body io.Reader, r ...*request) (*response, error)
+	Put(ctx context.Context, endpoint string, body io.Reader, r ...*Request) (*Response, error)
 
 	// Delete performs a DELETE request
-	Delete(ctx context.Context, endpoint string, r ...*request) (*response, error)
+	Delete(ctx context.Context, endpoint string, r ...*Request) (*Response, error)
+
+	// Head performs a HEAD request
+	Head(ctx context.Context, endpoint string, q *Q, r ...*Request) (*Response, error)
+
+	// Options performs an OPTIONS request
+	Options(ctx context.Context, endpoint string, r ...*Request) (*Response, error)
+
+	// Trace performs a TRACE request
+	Trace(ctx context.Context, endpoint string, r ...*Request) (*Response, error)
+
+	// Do performs a request with any method, including custom ones like PROPFIND or PURGE
+	Do(ctx context.Context, method, endpoint string, q *Q, body io.Reader, r ...*Request) (*Response, error)
+
+	// Send performs a request fully described by r, see Request.Method, Request.Endpoint and Request.Body
+	Send(ctx context.Context, r *Request) (*Response, error)
 }
@@ -0,0 +1,106 @@
+package hc_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/jacoz/go-http-client/pkg/hc"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClient is a test double implemented outside of the package, it answers every request with the same status
+type fakeClient struct {
+	status   int
+	calls    []string
+	requests []*hc.Request
+}
+
+var _ hc.Client = (*fakeClient)(nil)
+
+func (f *fakeClient) respond(method string, r ...*hc.Request) (*hc.Response, error) {
+	f.calls = append(f.calls, method)
+	if len(r) > 0 {
+		f.requests = append(f.requests, r[0])
+	}
+	return hc.NewResponse(&http.Response{StatusCode: f.status}), nil
+}
+
+func (f *fakeClient) Get(ctx context.Context, endpoint string, q *hc.Q, r ...*hc.Request) (*hc.Response, error) {
+	return f.respond(http.MethodGet, r...)
+}
+
+func (f *fakeClient) Post(ctx context.Context, endpoint string, body io.Reader, r ...*hc.Request) (*hc.Response, error) {
+	return f.respond(http.MethodPost, r...)
+}
+
+func (f *fakeClient) Patch(ctx context.Context, endpoint string, body io.Reader, r ...*hc.Request) (*hc.Response, error) {
+	return f.respond(http.MethodPatch, r...)
+}
+
+func (f *fakeClient) Put(ctx context.Context, endpoint string, body io.Reader, r ...*hc.Request) (*hc.Response, error) {
+	return f.respond(http.MethodPut, r...)
+}
+
+func (f *fakeClient) Delete(ctx context.Context, endpoint string, r ...*hc.Request) (*hc.Response, error) {
+	return f.respond(http.MethodDelete, r...)
+}
+
+func (f *fakeClient) Head(ctx context.Context, endpoint string, q *hc.Q, r ...*hc.Request) (*hc.Response, error) {
+	return f.respond(http.MethodHead, r...)
+}
+
+func (f *fakeClient) Options(ctx context.Context, endpoint string, r ...*hc.Request) (*hc.Response, error) {
+	return f.respond(http.MethodOptions, r...)
+}
+
+func (f *fakeClient) Trace(ctx context.Context, endpoint string, r ...*hc.Request) (*hc.Response, error) {
+	return f.respond(http.MethodTrace, r...)
+}
+
+func (f *fakeClient) Do(ctx context.Context, method, endpoint string, q *hc.Q, body io.Reader, r ...*hc.Request) (*hc.Response, error) {
+	return f.respond(method, r...)
+}
+
+func (f *fakeClient) Send(ctx context.Context, r *hc.Request) (*hc.Response, error) {
+	return f.respond(r.MethodName(), r)
+}
+
+func TestClient_TestDouble(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeClient{status: http.StatusCreated}
+
+	var c hc.Client = fake
+	res, err := c.Post(ctx, "/users", nil, hc.Req().WithJsonContentType())
+
+	assert.Nil(t, err)
+	assert.True(t, res.Created())
+	assert.Equal(t, []string{http.MethodPost}, fake.calls)
+}
+
+func TestClient_TestDoubleRequests(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeClient{status: http.StatusOK}
+
+	var c hc.Client = fake
+	_, err := c.Send(ctx, hc.Req().
+		Method(http.MethodPut).
+		Endpoint("/users/{id}").
+		Path("id", "42").
+		Query(hc.Q{"notify": "true"}).
+		WithHeader("X-Request-Id", "abc").
+		Body(hc.D{"name": "foo"}))
+	assert.Nil(t, err)
+
+	if assert.Len(t, fake.requests, 1) {
+		r := fake.requests[0]
+		assert.Equal(t, http.MethodPut, r.MethodName())
+		assert.Equal(t, "/users/{id}", r.EndpointName())
+		assert.Equal(t, map[string]string{"id": "42"}, r.PathParams())
+		assert.Equal(t, url.Values{"notify": {"true"}}, r.QueryParams())
+		assert.Equal(t, "abc", r.Header().Get("X-Request-Id"))
+		assert.Equal(t, hc.D{"name": "foo"}, r.BodyValue())
+	}
+}
@@ -0,0 +1,100 @@
+package hc
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// ErrUnsupportedContentType is returned when no codec is registered for the content type of a body
+var ErrUnsupportedContentType = errors.New("hc: unsupported content type")
+
+// Codec encodes and decodes the bodies of a content type, register it with Options.WithCodec
+type Codec interface {
+	ContentType() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+type (
+	// JsonCodec encodes and decodes application/json bodies
+	JsonCodec struct{}
+
+	// XmlCodec encodes and decodes application/xml bodies
+	XmlCodec struct{}
+)
+
+// defaultCodecs are always available, the codecs registered on the client take precedence
+var defaultCodecs = map[string]Codec{
+	jsonContentType:   JsonCodec{},
+	"application/xml": XmlCodec{},
+	"text/xml":        XmlCodec{},
+}
+
+func (JsonCodec) ContentType() string {
+	return jsonContentType
+}
+
+func (JsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes the first json value of data, an empty body returns io.EOF
+func (JsonCodec) Unmarshal(data []byte, v any) error {
+	return json.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func (XmlCodec) ContentType() string {
+	return "application/xml"
+}
+
+func (XmlCodec) Marshal(v any) ([]byte, error) {
+	return xml.Marshal(v)
+}
+
+func (XmlCodec) Unmarshal(data []byte, v any) error {
+	return xml.Unmarshal(data, v)
+}
+
+// lookupCodec finds the codec of a content type, structured syntax suffixes like +json and +xml fall back to the codec
+// of application/json and application/xml. An empty content type is treated as json.
+func lookupCodec(codecs map[string]Codec, contentType string) (Codec, error) {
+	mediaType := jsonContentType
+	if contentType != "" {
+		v, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, contentType)
+		}
+		mediaType = v
+	}
+
+	candidates := []string{mediaType}
+	if i := strings.LastIndex(mediaType, "+"); i >= 0 {
+		candidates = append(candidates, "application/"+mediaType[i+1:])
+	}
+
+	for _, c := range candidates {
+		if codec, ok := codecs[c]; ok {
+			return codec, nil
+		}
+		if codec, ok := defaultCodecs[c]; ok {
+			return codec, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, contentType)
+}
+
+// mediaType returns the lower case media type of a content type, without parameters
+func mediaType(contentType string) string {
+	v, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(contentType)
+	}
+
+	return v
+}
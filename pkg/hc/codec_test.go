@@ -0,0 +1,193 @@
+package hc
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jacoz/go-http-client/pkg/hc/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type textCodec struct{}
+
+func (textCodec) ContentType() string {
+	return "text/plain; charset=utf-8"
+}
+
+func (textCodec) Marshal(v any) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, errors.New("not a string")
+	}
+	return []byte(s), nil
+}
+
+func (textCodec) Unmarshal(data []byte, v any) error {
+	*v.(*string) = string(data)
+	return nil
+}
+
+func TestLookupCodec(t *testing.T) {
+	var tests = []struct {
+		name      string
+		codecs    map[string]Codec
+		input     string
+		want      Codec
+		wantError bool
+	}{
+		{"empty", nil, "", JsonCodec{}, false},
+		{"json", nil, "application/json; charset=utf-8", JsonCodec{}, false},
+		{"json suffix", nil, "application/problem+json", JsonCodec{}, false},
+		{"xml", nil, "text/xml", XmlCodec{}, false},
+		{"xml suffix", nil, "application/atom+xml", XmlCodec{}, false},
+		{"registered", map[string]Codec{"text/plain": textCodec{}}, "Text/Plain", textCodec{}, false},
+		{"registered replaces the default", map[string]Codec{jsonContentType: textCodec{}}, "application/json", textCodec{}, false},
+		{"unsupported", nil, "application/msgpack", nil, true},
+		{"invalid", nil, "/", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lookupCodec(tt.codecs, tt.input)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantError, errors.Is(err, ErrUnsupportedContentType))
+		})
+	}
+}
+
+func TestJsonCodec(t *testing.T) {
+	b, err := JsonCodec{}.Marshal(D{"foo": "bar"})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"foo":"bar"}`, string(b))
+
+	var got D
+	assert.Nil(t, JsonCodec{}.Unmarshal(b, &got))
+	assert.Equal(t, D{"foo": "bar"}, got)
+
+	assert.Equal(t, io.EOF, JsonCodec{}.Unmarshal(nil, &got))
+}
+
+func TestXmlCodec(t *testing.T) {
+	type data struct {
+		XMLName xml.Name `xml:"foo"`
+		Bar     string   `xml:"bar,attr"`
+	}
+
+	b, err := XmlCodec{}.Marshal(data{Bar: "baz"})
+	assert.Nil(t, err)
+	assert.Equal(t, `<foo bar="baz"></foo>`, string(b))
+
+	var got data
+	assert.Nil(t, XmlCodec{}.Unmarshal(b, &got))
+	assert.Equal(t, "baz", got.Bar)
+}
+
+func TestDefaultClient_Codecs(t *testing.T) {
+	ctx := context.Background()
+
+	var tests = []struct {
+		name            string
+		options         *Options
+		request         *Request
+		wantBody        string
+		wantContentType string
+		wantError       bool
+	}{
+		{
+			"should encode json by default",
+			Opts(),
+			Req().Body(D{"foo": "bar"}),
+			`{"foo":"bar"}`,
+			"application/json",
+			false,
+		},
+		{
+			"should encode with the codec of the request content type",
+			Opts(),
+			Req().WithContentType("application/xml").Body(struct {
+				XMLName xml.Name `xml:"foo"`
+			}{}),
+			`<foo></foo>`,
+			"application/xml",
+			false,
+		},
+		{
+			"should encode with the codec of the default content type",
+			Opts().WithCodec(textCodec{}).WithDefaultHeader("Content-Type", "text/plain"),
+			Req().Body("foo"),
+			`foo`,
+			"text/plain",
+			false,
+		},
+		{
+			"should not encode readers",
+			Opts(),
+			Req().Body(strings.NewReader("foo")),
+			`foo`,
+			"",
+			false,
+		},
+		{
+			"should fail without a codec",
+			Opts(),
+			Req().WithContentType("application/msgpack").Body(D{}),
+			"",
+			"",
+			true,
+		},
+		{
+			"should fail when marshalling fails",
+			Opts(),
+			Req().Body(D{"foo": func() {}}),
+			"",
+			"",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goHttpClientMock := mocks.NewGoHttpClient(t)
+			if !tt.wantError {
+				goHttpClientMock.On("Do", mock.Anything).Run(func(args mock.Arguments) {
+					req := args.Get(0).(*http.Request)
+					b, _ := io.ReadAll(req.Body)
+
+					assert.Equal(t, tt.wantBody, string(b))
+					assert.Equal(t, tt.wantContentType, req.Header.Get("Content-Type"))
+				}).Return(&http.Response{}, nil).Once()
+			}
+
+			c := newClient(tt.options.BaseUrl("https://example.com"))
+			c.client = goHttpClientMock
+
+			_, err := c.Post(ctx, "/foo", nil, tt.request)
+			assert.Equal(t, tt.wantError, err != nil)
+		})
+	}
+}
+
+func TestDefaultClient_Decode(t *testing.T) {
+	goHttpClientMock := mocks.NewGoHttpClient(t)
+	goHttpClientMock.On("Do", mock.Anything).Return(&http.Response{
+		Header: http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+		Body:   io.NopCloser(strings.NewReader("foo")),
+	}, nil)
+
+	c := newClient(Opts().BaseUrl("https://example.com").WithCodec(textCodec{}))
+	c.client = goHttpClientMock
+
+	res, err := c.Get(context.Background(), "/foo", nil)
+	assert.Nil(t, err)
+
+	var got string
+	assert.Nil(t, res.Decode(&got))
+	assert.Equal(t, "foo", got)
+}
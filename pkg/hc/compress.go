@@ -0,0 +1,123 @@
+package hc
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Compressor compresses the request bodies, Encoding is the value of the Content-Encoding header. Register it with
+// Options.Compress or Request.Compress, eg. to plug in zstd or brotli.
+type Compressor interface {
+	Encoding() string
+	Compress(w io.Writer) (io.WriteCloser, error)
+}
+
+type (
+	// GzipCompressor compresses the bodies with gzip
+	GzipCompressor struct{}
+
+	// DeflateCompressor compresses the bodies with deflate, in the zlib format as defined by the http specification
+	DeflateCompressor struct{}
+)
+
+func (GzipCompressor) Encoding() string {
+	return "gzip"
+}
+
+func (GzipCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+func (DeflateCompressor) Encoding() string {
+	return "deflate"
+}
+
+func (DeflateCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	return zlib.NewWriter(w), nil
+}
+
+// compress replaces the body of the request with its compressed version. Bodies smaller than the threshold and
+// bodies already encoded are sent as they are, bodies of unknown size are always compressed.
+func compress(req *http.Request, c Compressor, threshold int64) {
+	if c == nil || req.Body == nil || req.Body == http.NoBody || req.Header.Get("Content-Encoding") != "" {
+		return
+	}
+	if req.ContentLength > 0 && req.ContentLength < threshold {
+		return
+	}
+
+	req.Body = newCompressedBody(c, req.Body)
+	req.ContentLength = -1
+	req.Header.Set("Content-Encoding", c.Encoding())
+
+	if getBody := req.GetBody; getBody != nil {
+		req.GetBody = func() (io.ReadCloser, error) {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+
+			return newCompressedBody(c, body), nil
+		}
+	}
+}
+
+// compressedBody compresses the body through a pipe while it's read, so that the payload is never held twice in memory
+type compressedBody struct {
+	compressor Compressor
+	body       io.ReadCloser
+
+	once   sync.Once
+	reader *io.PipeReader
+	writer *io.PipeWriter
+}
+
+func newCompressedBody(c Compressor, body io.ReadCloser) *compressedBody {
+	pr, pw := io.Pipe()
+
+	return &compressedBody{
+		compressor: c,
+		body:       body,
+		reader:     pr,
+		writer:     pw,
+	}
+}
+
+// Read starts the compression in the background the first time it's called
+func (b *compressedBody) Read(p []byte) (int, error) {
+	b.once.Do(func() {
+		go func() {
+			b.writer.CloseWithError(b.write())
+		}()
+	})
+
+	return b.reader.Read(p)
+}
+
+// Close stops the compression, the original body is closed as well if it was never read
+func (b *compressedBody) Close() error {
+	b.once.Do(func() {
+		b.body.Close()
+	})
+
+	return b.reader.Close()
+}
+
+func (b *compressedBody) write() error {
+	defer b.body.Close()
+
+	w, err := b.compressor.Compress(b.writer)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(w, b.body); err != nil {
+		w.Close()
+		return err
+	}
+
+	return w.Close()
+}
@@ -0,0 +1,158 @@
+package hc
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// identityCompressor sends the body as it is, with a custom encoding
+type identityCompressor struct{}
+
+func (identityCompressor) Encoding() string {
+	return "x-identity"
+}
+
+func (identityCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	return nopWriteCloser{w}, nil
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+func TestCompress(t *testing.T) {
+	var tests = []struct {
+		name         string
+		compressor   Compressor
+		threshold    int64
+		body         io.Reader
+		header       http.Header
+		wantEncoding string
+	}{
+		{"without compressor", nil, 0, strings.NewReader("foo"), http.Header{}, ""},
+		{"without body", GzipCompressor{}, 0, nil, http.Header{}, ""},
+		{"below the threshold", GzipCompressor{}, 4, strings.NewReader("foo"), http.Header{}, ""},
+		{"above the threshold", GzipCompressor{}, 3, strings.NewReader("foo"), http.Header{}, "gzip"},
+		{"unknown size", GzipCompressor{}, 4, io.MultiReader(strings.NewReader("foo")), http.Header{}, "gzip"},
+		{"already encoded", GzipCompressor{}, 0, strings.NewReader("foo"), http.Header{"Content-Encoding": {"br"}}, "br"},
+		{"deflate", DeflateCompressor{}, 0, strings.NewReader("foo"), http.Header{}, "deflate"},
+		{"custom", identityCompressor{}, 0, strings.NewReader("foo"), http.Header{}, "x-identity"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, "https://example.com", tt.body)
+			req.Header = tt.header
+
+			compress(req, tt.compressor, tt.threshold)
+			assert.Equal(t, tt.wantEncoding, req.Header.Get("Content-Encoding"))
+
+			if tt.body != nil {
+				assert.Equal(t, "foo", decompressed(t, req.Header.Get("Content-Encoding"), req.Body))
+			}
+		})
+	}
+}
+
+func TestCompress_GetBody(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "https://example.com", strings.NewReader("foo"))
+	compress(req, GzipCompressor{}, 0)
+
+	for i := 0; i < 2; i++ {
+		body, err := req.GetBody()
+		assert.Nil(t, err)
+		assert.Equal(t, "foo", decompressed(t, "gzip", body))
+	}
+}
+
+func TestDefaultClient_Compress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Content-Encoding", r.Header.Get("Content-Encoding"))
+		w.Write([]byte(decompressed(t, r.Header.Get("Content-Encoding"), r.Body)))
+	}))
+	defer server.Close()
+
+	body := strings.Repeat("foo", 100)
+
+	var tests = []struct {
+		name         string
+		options      *Options
+		request      *Request
+		wantEncoding string
+	}{
+		{
+			"without compression",
+			Opts(),
+			Req(),
+			"",
+		},
+		{
+			"above the threshold of the client",
+			Opts().Compress(GzipCompressor{}, 100),
+			Req(),
+			"gzip",
+		},
+		{
+			"below the threshold of the client",
+			Opts().Compress(GzipCompressor{}, 1000),
+			Req(),
+			"",
+		},
+		{
+			"compressor of the request",
+			Opts().Compress(GzipCompressor{}, 1000),
+			Req().Compress(DeflateCompressor{}),
+			"deflate",
+		},
+		{
+			"compression disabled by the request",
+			Opts().Compress(GzipCompressor{}, 0),
+			Req().WithoutCompression(),
+			"",
+		},
+		{
+			"compression with retries",
+			Opts().Compress(GzipCompressor{}, 0).WithRetry(Retry()),
+			Req(),
+			"gzip",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.options.BaseUrl(server.URL)).Post(context.Background(), "/", strings.NewReader(body), tt.request)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantEncoding, got.Get().Header.Get("X-Content-Encoding"))
+			assert.Equal(t, body, string(got.Debug()))
+		})
+	}
+}
+
+func decompressed(t *testing.T, encoding string, body io.Reader) string {
+	var err error
+	switch encoding {
+	case "gzip":
+		body, err = gzip.NewReader(body)
+	case "deflate":
+		body, err = zlib.NewReader(body)
+	}
+	assert.Nil(t, err)
+
+	b, err := io.ReadAll(body)
+	assert.Nil(t, err)
+
+	return string(b)
+}
@@ -0,0 +1,132 @@
+package hc
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Decompressor decodes the response bodies, Encoding is the value of the Content-Encoding header it handles. Register
+// it with Options.WithDecompressor, eg. to plug in brotli or zstd.
+type Decompressor interface {
+	Encoding() string
+	Decompress(r io.Reader) (io.ReadCloser, error)
+}
+
+type (
+	// GzipDecompressor decodes gzip bodies
+	GzipDecompressor struct{}
+
+	// DeflateDecompressor decodes deflate bodies, in the zlib format as defined by the http specification
+	DeflateDecompressor struct{}
+)
+
+// defaultDecompressors are always available, the decompressors registered on the client take precedence
+var defaultDecompressors = map[string]Decompressor{
+	"gzip":    GzipDecompressor{},
+	"deflate": DeflateDecompressor{},
+}
+
+func (GzipDecompressor) Encoding() string {
+	return "gzip"
+}
+
+func (GzipDecompressor) Decompress(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+func (DeflateDecompressor) Encoding() string {
+	return "deflate"
+}
+
+func (DeflateDecompressor) Decompress(r io.Reader) (io.ReadCloser, error) {
+	return zlib.NewReader(r)
+}
+
+// acceptEncoding lists the encodings that can be decoded, as the value of the Accept-Encoding header
+func acceptEncoding(decompressors map[string]Decompressor) string {
+	var extra []string
+	for k := range decompressors {
+		if _, ok := defaultDecompressors[k]; !ok {
+			extra = append(extra, k)
+		}
+	}
+	sort.Strings(extra)
+
+	return strings.Join(append([]string{"gzip", "deflate"}, extra...), ", ")
+}
+
+// decompress replaces the body of the response with its decoded version, removing the Content-Encoding and
+// Content-Length headers like the transport does for gzip. Responses with an unknown encoding are left as they are.
+func decompress(res *http.Response, decompressors map[string]Decompressor) {
+	v := res.Header.Get("Content-Encoding")
+	if res.Body == nil || res.Body == http.NoBody || v == "" {
+		return
+	}
+
+	// the encodings are listed in the order they were applied, so they are decoded backwards
+	var chain []Decompressor
+	encodings := strings.Split(v, ",")
+	for i := len(encodings) - 1; i >= 0; i-- {
+		e := strings.ToLower(strings.TrimSpace(encodings[i]))
+		if e == "identity" || e == "" {
+			continue
+		}
+
+		d, ok := decompressors[e]
+		if !ok {
+			d, ok = defaultDecompressors[e]
+		}
+		if !ok {
+			return
+		}
+		chain = append(chain, d)
+	}
+
+	res.Body = &decompressedBody{body: res.Body, chain: chain}
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	res.ContentLength = -1
+	res.Uncompressed = true
+}
+
+// decompressedBody decodes the body the first time it's read, so that nothing is read from the network before
+type decompressedBody struct {
+	body  io.ReadCloser
+	chain []Decompressor
+
+	once    sync.Once
+	reader  io.Reader
+	closers []io.Closer
+}
+
+func (b *decompressedBody) Read(p []byte) (int, error) {
+	b.once.Do(func() {
+		var r io.Reader = b.body
+		for _, d := range b.chain {
+			rc, err := d.Decompress(r)
+			if err != nil {
+				r = errorReader{err}
+				break
+			}
+
+			b.closers = append(b.closers, rc)
+			r = rc
+		}
+		b.reader = r
+	})
+
+	return b.reader.Read(p)
+}
+
+func (b *decompressedBody) Close() error {
+	for _, c := range b.closers {
+		c.Close()
+	}
+
+	return b.body.Close()
+}
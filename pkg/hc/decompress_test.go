@@ -0,0 +1,173 @@
+package hc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// identityDecompressor reads the body as it is, for a custom encoding
+type identityDecompressor struct{}
+
+func (identityDecompressor) Encoding() string {
+	return "x-identity"
+}
+
+func (identityDecompressor) Decompress(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(r), nil
+}
+
+func gzipped(s string) []byte {
+	buf := new(bytes.Buffer)
+	w := gzip.NewWriter(buf)
+	w.Write([]byte(s))
+	w.Close()
+
+	return buf.Bytes()
+}
+
+func deflated(s string) []byte {
+	buf := new(bytes.Buffer)
+	w := zlib.NewWriter(buf)
+	w.Write([]byte(s))
+	w.Close()
+
+	return buf.Bytes()
+}
+
+func TestDecompress(t *testing.T) {
+	var tests = []struct {
+		name          string
+		decompressors map[string]Decompressor
+		encoding      string
+		body          []byte
+		want          string
+		wantEncoding  string
+	}{
+		{"not encoded", nil, "", []byte("foo"), "foo", ""},
+		{"identity", nil, "identity", []byte("foo"), "foo", ""},
+		{"gzip", nil, "gzip", gzipped("foo"), "foo", ""},
+		{"deflate", nil, "Deflate", deflated("foo"), "foo", ""},
+		{"multiple encodings", nil, "deflate, gzip", gzipped(string(deflated("foo"))), "foo", ""},
+		{"custom", map[string]Decompressor{"x-identity": identityDecompressor{}}, "x-identity", []byte("foo"), "foo", ""},
+		{"unknown", nil, "br", []byte("foo"), "foo", "br"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &http.Response{
+				Header: http.Header{"Content-Encoding": {tt.encoding}},
+				Body:   io.NopCloser(bytes.NewReader(tt.body)),
+			}
+			decompress(res, tt.decompressors)
+
+			b, err := io.ReadAll(res.Body)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, string(b))
+			assert.Equal(t, tt.wantEncoding, res.Header.Get("Content-Encoding"))
+			assert.Nil(t, res.Body.Close())
+		})
+	}
+}
+
+func TestDecompress_InvalidBody(t *testing.T) {
+	res := &http.Response{
+		Header: http.Header{"Content-Encoding": {"gzip"}},
+		Body:   io.NopCloser(strings.NewReader("foo")),
+	}
+	decompress(res, nil)
+
+	_, err := io.ReadAll(res.Body)
+	assert.NotNil(t, err)
+}
+
+func TestAcceptEncoding(t *testing.T) {
+	assert.Equal(t, "gzip, deflate", acceptEncoding(nil))
+	assert.Equal(t, "gzip, deflate, br, zstd", acceptEncoding(map[string]Decompressor{
+		"zstd": identityDecompressor{},
+		"br":   identityDecompressor{},
+		"gzip": GzipDecompressor{},
+	}))
+}
+
+func TestDefaultClient_Decompress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Accept-Encoding", r.Header.Get("Accept-Encoding"))
+		if strings.Contains(r.Header.Get("Accept-Encoding"), "deflate") {
+			w.Header().Set("Content-Encoding", "deflate")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write(deflated("foo"))
+			return
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("foo"))
+	}))
+	defer server.Close()
+
+	var tests = []struct {
+		name               string
+		options            *Options
+		request            *Request
+		wantAcceptEncoding string
+		wantBody           []byte
+	}{
+		{
+			"should let the transport negotiate",
+			Opts(),
+			Req(),
+			"gzip",
+			[]byte("foo"),
+		},
+		{
+			"should advertise the supported encodings",
+			Opts().AcceptEncoding().WithDecompressor(identityDecompressor{}),
+			Req(),
+			"gzip, deflate, x-identity",
+			[]byte("foo"),
+		},
+		{
+			"should decode the encoding of the request",
+			Opts().AcceptEncoding(),
+			Req().WithHeader("Accept-Encoding", "deflate"),
+			"deflate",
+			[]byte("foo"),
+		},
+		{
+			"should not decode when disabled",
+			Opts().DisableDecompression(),
+			Req().WithHeader("Accept-Encoding", "deflate"),
+			"deflate",
+			deflated("foo"),
+		},
+		{
+			"should not decode when disabled by the request",
+			Opts().AcceptEncoding(),
+			Req().WithoutDecompression().WithHeader("Accept-Encoding", "deflate"),
+			"deflate",
+			deflated("foo"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.options.BaseUrl(server.URL).ErrorOnStatus(true)).Get(context.Background(), "/", nil, tt.request)
+
+			var httpErr *HTTPError
+			if assert.True(t, errors.As(err, &httpErr)) {
+				assert.Equal(t, tt.wantBody, httpErr.Body)
+			}
+			assert.Equal(t, tt.wantAcceptEncoding, got.Get().Header.Get("X-Accept-Encoding"))
+			assert.Equal(t, tt.wantBody, got.Debug())
+		})
+	}
+}
@@ -1,24 +1,36 @@
 package hc
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/url"
-	"time"
 )
 
 type goHttpClient interface {
-	Do(req *http.Request) (*http.Response, error)
+	Doer
 }
 
 type defaultClient struct {
-	options options
+	options Options
 	client  goHttpClient
+	baseUrl *url.URL
+
+	// err is returned by every request when the options are not valid
+	err error
+}
+
+var _ Client = (*defaultClient)(nil)
+
+// New creates a client with the given options, or with the default ones
+func New(opts ...*Options) Client {
+	return newClient(opts...)
 }
 
-func New(opts ...*options) *defaultClient {
-	var o options
+func newClient(opts ...*Options) *defaultClient {
+	var o Options
 	if len(opts) > 0 {
 		o = *opts[0]
 	} else {
@@ -26,88 +38,374 @@ func New(opts ...*options) *defaultClient {
 		o = *v
 	}
 
+	baseUrl, err := parseBaseUrl(o.baseUrl)
+
 	return &defaultClient{
 		options: o,
-		client: &http.Client{
-			Timeout: time.Duration(o.timeout) * time.Second,
-		},
+		client:  o.newHttpClient(),
+		baseUrl: baseUrl,
+		err:     err,
 	}
 }
 
-func (c *defaultClient) Get(ctx context.Context, endpoint string, q *Q, r ...*request) (*response, error) {
+func (c *defaultClient) Get(ctx context.Context, endpoint string, q *Q, r ...*Request) (*Response, error) {
 	return c.do(ctx, http.MethodGet, endpoint, q, nil, r...)
 }
 
-func (c *defaultClient) Post(ctx context.Context, endpoint string, body io.Reader, r ...*request) (*response, error) {
+func (c *defaultClient) Post(ctx context.Context, endpoint string, body io.Reader, r ...*Request) (*Response, error) {
 	return c.do(ctx, http.MethodPost, endpoint, nil, body, r...)
 }
 
-func (c *defaultClient) Patch(ctx context.Context, endpoint string, body io.Reader, r ...*request) (*response, error) {
+func (c *defaultClient) Patch(ctx context.Context, endpoint string, body io.Reader, r ...*Request) (*Response, error) {
 	return c.do(ctx, http.MethodPatch, endpoint, nil, body, r...)
 }
 
-func (c *defaultClient) Put(ctx context.Context, endpoint string, body io.Reader, r ...*request) (*response, error) {
+func (c *defaultClient) Put(ctx context.Context, endpoint string, body io.Reader, r ...*Request) (*Response, error) {
 	return c.do(ctx, http.MethodPut, endpoint, nil, body, r...)
 }
 
-func (c *defaultClient) Delete(ctx context.Context, endpoint string, r ...*request) (*response, error) {
+func (c *defaultClient) Delete(ctx context.Context, endpoint string, r ...*Request) (*Response, error) {
 	return c.do(ctx, http.MethodDelete, endpoint, nil, nil, r...)
 }
 
-func (c *defaultClient) do(ctx context.Context, method, endpoint string, q *Q, body io.Reader, r ...*request) (*response, error) {
-	fullUrl := c.options.baseUrl + endpoint
+func (c *defaultClient) Head(ctx context.Context, endpoint string, q *Q, r ...*Request) (*Response, error) {
+	return c.do(ctx, http.MethodHead, endpoint, q, nil, r...)
+}
+
+func (c *defaultClient) Options(ctx context.Context, endpoint string, r ...*Request) (*Response, error) {
+	return c.do(ctx, http.MethodOptions, endpoint, nil, nil, r...)
+}
+
+func (c *defaultClient) Trace(ctx context.Context, endpoint string, r ...*Request) (*Response, error) {
+	return c.do(ctx, http.MethodTrace, endpoint, nil, nil, r...)
+}
+
+func (c *defaultClient) Do(ctx context.Context, method, endpoint string, q *Q, body io.Reader, r ...*Request) (*Response, error) {
+	return c.do(ctx, method, endpoint, q, body, r...)
+}
+
+// ErrNilRequest is returned by Send when the request is nil
+var ErrNilRequest = errors.New("hc: nil request")
+
+func (c *defaultClient) Send(ctx context.Context, r *Request) (*Response, error) {
+	if r == nil {
+		return nil, ErrNilRequest
+	}
+
+	method := r.method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	return c.do(ctx, method, r.endpoint, nil, nil, r)
+}
+
+func (c *defaultClient) do(ctx context.Context, method, endpoint string, q *Q, body io.Reader, r ...*Request) (*Response, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	if len(r) > 0 && r[0] != nil {
+		if r[0].err != nil {
+			return nil, r[0].err
+		}
+	}
+
+	var contentType string
+	if body == nil && len(r) > 0 && r[0] != nil && r[0].body != nil {
+		var err error
+		if body, contentType, err = c.encode(r[0].body, r...); err != nil {
+			return nil, err
+		}
+	}
+	if b, ok := body.(contentTyper); ok {
+		contentType = b.ContentType()
+	}
+	if b, ok := body.(unwrapper); ok {
+		body = b.unwrap()
+	}
+
+	if isTemplate(endpoint) {
+		var params map[string]string
+		if len(r) > 0 && r[0] != nil {
+			params = r[0].pathParams
+		}
+
+		path, err := expandPath(endpoint, params)
+		if err != nil {
+			return nil, err
+		}
+
+		template, _ := splitPath(endpoint)
+		ctx = withEndpointTemplate(ctx, template)
+		endpoint = path
+	}
+	if len(r) > 0 && r[0] != nil && r[0].template != "" {
+		ctx = withEndpointTemplate(ctx, r[0].template)
+	}
 
-	req, err := http.NewRequestWithContext(ctx, method, fullUrl, body)
+	fullUrl, err := resolveUrl(c.baseUrl, endpoint)
 	if err != nil {
 		return nil, err
 	}
+
+	ctx, cancel := c.withTimeout(ctx, r...)
+
+	var timings *timingsRecorder
+	if c.options.timings || (len(r) > 0 && r[0] != nil && r[0].timings) {
+		timings = newTimingsRecorder()
+		ctx = timings.withTrace(ctx)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fullUrl.String(), body)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
 	c.setHeaders(req, r...)
 	c.setQueryString(req, q, r...)
+	c.setContentType(req, contentType, r...)
+	c.compress(req, r...)
+	if c.options.acceptEncoding && c.decompress(r...) && req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding(c.options.decompressors))
+	}
+
+	var span Span
+	if c.options.tracer != nil {
+		var spanCtx context.Context
+		spanCtx, span = startSpan(c.options.tracer, req)
+		req = req.WithContext(spanCtx)
+	}
 
-	res, err := c.client.Do(req)
+	res, err := c.send(req, r...)
+	if span != nil {
+		endSpan(span, res, err)
+	}
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 
-	return &response{res}, nil
+	if res.Body != nil {
+		res.Body = &cancelOnClose{res.Body, cancel}
+	} else {
+		cancel()
+	}
+
+	if timings != nil {
+		timings.receive()
+		if res.Body != nil {
+			res.Body = &timedBody{res.Body, timings}
+		}
+	}
+
+	if c.decompress(r...) {
+		decompress(res, c.options.decompressors)
+	}
+
+	resp := c.newResponse(res, r...)
+	resp.timings = timings
+
+	if c.errorOnStatus(r...) && !isSuccess(res.StatusCode) {
+		return resp, newHTTPError(req, resp)
+	}
+
+	return resp, nil
+}
+
+func (c *defaultClient) newResponse(res *http.Response, r ...*Request) *Response {
+	resp := NewResponse(res)
+	resp.maxBytes = c.options.maxBodyBytes
+	resp.codecs = c.options.codecs
+
+	if len(r) > 0 && r[0] != nil {
+		resp.stream = r[0].stream
+		if r[0].maxBodyBytes != 0 {
+			resp.maxBytes = r[0].maxBodyBytes
+		}
+	}
+
+	return resp
+}
+
+// compress compresses the body with the compressor of the request or of the client
+func (c *defaultClient) compress(req *http.Request, r ...*Request) {
+	compressor, threshold := c.options.compressor, c.options.compressAbove
+	if len(r) > 0 && r[0] != nil {
+		if r[0].noCompression {
+			return
+		}
+		if r[0].compressor != nil {
+			compressor, threshold = r[0].compressor, 0
+		}
+	}
+
+	compress(req, compressor, threshold)
+}
+
+// decompress tells if the response body should be decoded
+func (c *defaultClient) decompress(r ...*Request) bool {
+	if len(r) > 0 && r[0] != nil && r[0].noDecompress {
+		return false
+	}
+
+	return !c.options.noDecompress
+}
+
+// setContentType sets the Content-Type of the body, unless the request sets its own
+func (c *defaultClient) setContentType(req *http.Request, contentType string, r ...*Request) {
+	if contentType == "" || (len(r) > 0 && r[0] != nil && r[0].headers.Get("Content-Type") != "") {
+		return
+	}
+
+	req.Header.Set("Content-Type", contentType)
+}
+
+// encode converts the body of the request to a reader, values that aren't readers are marshalled with the codec of the
+// Content-Type header. The content type of the encoded body is returned as well.
+func (c *defaultClient) encode(v any, r ...*Request) (io.Reader, string, error) {
+	if reader, ok := v.(io.Reader); ok {
+		return reader, "", nil
+	}
+
+	contentType := c.options.defaultHeaders.Get("Content-Type")
+	if len(r) > 0 && r[0] != nil && r[0].headers.Get("Content-Type") != "" {
+		contentType = r[0].headers.Get("Content-Type")
+	}
+
+	codec, err := lookupCodec(c.options.codecs, contentType)
+	if err != nil {
+		return nil, "", err
+	}
+
+	b, err := codec.Marshal(v)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if contentType == "" {
+		contentType = codec.ContentType()
+	}
+
+	return bytes.NewReader(b), contentType, nil
+}
+
+func (c *defaultClient) errorOnStatus(r ...*Request) bool {
+	if len(r) > 0 && r[0] != nil && r[0].errorOnStatus != nil {
+		return *r[0].errorOnStatus
+	}
+
+	return c.options.errorOnStatus
 }
 
-func (c *defaultClient) setHeaders(req *http.Request, r ...*request) {
-	if len(c.options.defaultHeaders) > 0 {
-		for k, v := range c.options.defaultHeaders {
-			req.Header.Set(k, v)
+// withTimeout derives a context with the deadline of the request, if any. The returned cancel function must be
+// called once the response body is not needed anymore.
+func (c *defaultClient) withTimeout(ctx context.Context, r ...*Request) (context.Context, context.CancelFunc) {
+	if len(r) > 0 && r[0] != nil && r[0].timeout > 0 {
+		return context.WithTimeout(ctx, r[0].timeout)
+	}
+
+	return ctx, func() {}
+}
+
+// httpClient returns the client used to send the request, when the request has its own deadline the total timeout of
+// the http client is dropped so that the deadline can also be longer
+func (c *defaultClient) httpClient(r ...*Request) goHttpClient {
+	if len(r) > 0 && r[0] != nil && r[0].timeout > 0 {
+		if hc, ok := c.client.(*http.Client); ok && hc.Timeout > 0 {
+			cp := *hc
+			cp.Timeout = 0
+			return &cp
 		}
 	}
 
-	if r != nil && len(r) > 0 && len(r[0].headers) > 0 {
-		for k, v := range r[0].headers {
-			req.Header.Set(k, v)
+	return c.client
+}
+
+// send passes the request through the middlewares of the client and of the request, the retry policy wraps all of
+// them so that every attempt goes through the whole chain. The authenticator comes right after the retry policy, so
+// that the middlewares see the credentials, while tracing, metrics and logging come last to see what's actually sent
+func (c *defaultClient) send(req *http.Request, r ...*Request) (*http.Response, error) {
+	mw := c.options.middlewares
+	policy := c.options.retry
+	auth := c.options.auth
+	if len(r) > 0 && r[0] != nil {
+		mw = append(mw[:len(mw):len(mw)], r[0].middlewares...)
+		if r[0].retry != nil {
+			policy = r[0].retry
 		}
+		if r[0].auth != nil {
+			auth = r[0].auth
+		}
+	}
+
+	if auth != nil {
+		mw = append([]Middleware{authMiddleware(auth)}, mw...)
 	}
+	if policy != nil {
+		mw = append([]Middleware{policy.Middleware()}, mw...)
+	}
+	if c.options.tracer != nil || c.options.propagator != nil {
+		mw = append(mw[:len(mw):len(mw)], tracingMiddleware(c.options.tracer, c.options.propagator))
+	}
+	if c.options.metrics != nil {
+		mw = append(mw[:len(mw):len(mw)], metricsMiddleware(c.options.metrics))
+	}
+	if c.options.logging != nil {
+		mw = append(mw[:len(mw):len(mw)], c.options.logging.Middleware())
+	}
+
+	return chain(c.httpClient(r...), mw...).Do(req)
 }
 
-func (c *defaultClient) setQueryString(req *http.Request, q *Q, r ...*request) {
-	res := url.Values{}
+// setHeaders applies the default headers, then the ones of the request and finally the ones set by the client, every
+// level replaces all the values of the keys it defines
+func (c *defaultClient) setHeaders(req *http.Request, r ...*Request) {
+	res := http.Header{}
+	mergeHeader(res, c.options.defaultHeaders)
 
-	if len(c.options.defaultQuery) > 0 {
-		for k, v := range c.options.defaultQuery {
-			res.Set(k, v)
+	if len(r) > 0 && r[0] != nil {
+		for _, k := range r[0].removeHeaders {
+			res.Del(k)
 		}
+		mergeHeader(res, r[0].headers)
 	}
 
-	if len(r) > 0 && r != nil {
-		for k, v := range r[0].query {
-			res.Set(k, v)
+	mergeHeader(req.Header, res)
+}
+
+// setQueryString applies the default query, then the one already in the url (from the base url and the endpoint),
+// the one of the request and finally the one passed to the method, every level replaces all the values of the keys
+// it defines
+func (c *defaultClient) setQueryString(req *http.Request, q *Q, r ...*Request) {
+	res := url.Values{}
+	mergeValues(res, c.options.defaultQuery)
+	mergeValues(res, req.URL.Query())
+
+	if len(r) > 0 && r[0] != nil {
+		for _, k := range r[0].removeQuery {
+			res.Del(k)
 		}
+		mergeValues(res, r[0].query)
 	}
 
 	if q != nil {
-		for k, v := range *q {
-			res.Set(k, v)
-		}
+		mergeValues(res, q.values())
 	}
 
 	if len(res) > 0 {
 		req.URL.RawQuery = res.Encode()
 	}
 }
+
+// cancelOnClose releases the context of the request when the response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -10,8 +10,11 @@ import (
 	"github.com/stretchr/testify/mock"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestDefaultClient_Get(t *testing.T) {
@@ -19,11 +22,11 @@ func TestDefaultClient_Get(t *testing.T) {
 
 	var tests = []struct {
 		name      string
-		options   *options
+		options   *Options
 		endpoint  string
 		query     *Q
-		request   *request
-		want      *response
+		request   *Request
+		want      *Response
 		wantError error
 	}{
 		{
@@ -43,7 +46,7 @@ func TestDefaultClient_Get(t *testing.T) {
 				"foo": "bar",
 			},
 			Req().WithHeader("req", "true"),
-			&response{
+			&Response{
 				response: &http.Response{},
 			},
 			nil,
@@ -59,7 +62,7 @@ func TestDefaultClient_Get(t *testing.T) {
 				"foo": "bar",
 			},
 			Req(),
-			&response{
+			&Response{
 				response: &http.Response{},
 			},
 			nil,
@@ -90,7 +93,7 @@ func TestDefaultClient_Get(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := New(tt.options)
+			c := newClient(tt.options)
 			c.client = goHttpClientMock
 			got, err := c.Get(ctx, tt.endpoint, tt.query, tt.request)
 
@@ -105,10 +108,10 @@ func TestDefaultClient_Post(t *testing.T) {
 
 	var tests = []struct {
 		name      string
-		options   *options
+		options   *Options
 		endpoint  string
 		body      io.Reader
-		want      *response
+		want      *Response
 		wantError error
 	}{
 		{
@@ -124,7 +127,7 @@ func TestDefaultClient_Post(t *testing.T) {
 			Opts().BaseUrl("https://example.com/api/v1"),
 			"/foo/bar",
 			strings.NewReader(`{"foo": "bar"}`),
-			&response{
+			&Response{
 				response: &http.Response{},
 			},
 			nil,
@@ -137,7 +140,7 @@ func TestDefaultClient_Post(t *testing.T) {
 				WithDefaultQuery(Q{"default": "query"}),
 			"/foo/bar2",
 			strings.NewReader(`{"foo": "bar 2"}`),
-			&response{
+			&Response{
 				response: &http.Response{},
 			},
 			nil,
@@ -172,7 +175,7 @@ func TestDefaultClient_Post(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := New(tt.options)
+			c := newClient(tt.options)
 			c.client = goHttpClientMock
 			got, err := c.Post(ctx, tt.endpoint, tt.body)
 
@@ -187,10 +190,10 @@ func TestDefaultClient_Patch(t *testing.T) {
 
 	var tests = []struct {
 		name      string
-		options   *options
+		options   *Options
 		endpoint  string
 		body      io.Reader
-		want      *response
+		want      *Response
 		wantError error
 	}{
 		{
@@ -206,7 +209,7 @@ func TestDefaultClient_Patch(t *testing.T) {
 			Opts().BaseUrl("https://example.com/api/v1"),
 			"/foo/bar",
 			strings.NewReader(`{"foo": "bar"}`),
-			&response{
+			&Response{
 				response: &http.Response{},
 			},
 			nil,
@@ -232,7 +235,7 @@ func TestDefaultClient_Patch(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := New(tt.options)
+			c := newClient(tt.options)
 			c.client = goHttpClientMock
 			got, err := c.Patch(ctx, tt.endpoint, tt.body)
 
@@ -247,10 +250,10 @@ func TestDefaultClient_Put(t *testing.T) {
 
 	var tests = []struct {
 		name      string
-		options   *options
+		options   *Options
 		endpoint  string
 		body      io.Reader
-		want      *response
+		want      *Response
 		wantError error
 	}{
 		{
@@ -266,7 +269,7 @@ func TestDefaultClient_Put(t *testing.T) {
 			Opts().BaseUrl("https://example.com/api/v1"),
 			"/foo/bar",
 			strings.NewReader(`{"foo": "bar"}`),
-			&response{
+			&Response{
 				response: &http.Response{},
 			},
 			nil,
@@ -292,7 +295,7 @@ func TestDefaultClient_Put(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := New(tt.options)
+			c := newClient(tt.options)
 			c.client = goHttpClientMock
 			got, err := c.Put(ctx, tt.endpoint, tt.body)
 
@@ -307,9 +310,9 @@ func TestDefaultClient_Delete(t *testing.T) {
 
 	var tests = []struct {
 		name      string
-		options   *options
+		options   *Options
 		endpoint  string
-		want      *response
+		want      *Response
 		wantError error
 	}{
 		{
@@ -323,7 +326,7 @@ func TestDefaultClient_Delete(t *testing.T) {
 			"should return a successful response",
 			Opts().BaseUrl("https://example.com/api/v1"),
 			"/foo/bar",
-			&response{
+			&Response{
 				response: &http.Response{},
 			},
 			nil,
@@ -345,7 +348,7 @@ func TestDefaultClient_Delete(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := New(tt.options)
+			c := newClient(tt.options)
 			c.client = goHttpClientMock
 			got, err := c.Delete(ctx, tt.endpoint)
 
@@ -354,3 +357,293 @@ func TestDefaultClient_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestDefaultClient_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("done"))
+	}))
+	defer server.Close()
+
+	var tests = []struct {
+		name      string
+		options   *Options
+		request   *Request
+		wantError bool
+	}{
+		{
+			"should fail with the total timeout",
+			Opts().BaseUrl(server.URL).TotalTimeout(20 * time.Millisecond),
+			Req(),
+			true,
+		},
+		{
+			"should fail with a shorter request timeout",
+			Opts().BaseUrl(server.URL),
+			Req().Timeout(20 * time.Millisecond),
+			true,
+		},
+		{
+			"should succeed with a longer request timeout",
+			Opts().BaseUrl(server.URL).TotalTimeout(20 * time.Millisecond),
+			Req().Timeout(time.Second),
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.options).Get(context.Background(), "/", nil, tt.request)
+
+			assert.Equal(t, tt.wantError, err != nil)
+			if !tt.wantError {
+				assert.Equal(t, "done", string(got.Debug()))
+			}
+		})
+	}
+}
+
+func TestDefaultClient_ResponseBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"foo":"bar"}`))
+	}))
+	defer server.Close()
+
+	var tests = []struct {
+		name      string
+		options   *Options
+		request   *Request
+		want      string
+		wantError bool
+	}{
+		{
+			"should buffer the body",
+			Opts().BaseUrl(server.URL),
+			Req(),
+			`{"foo":"bar"}`,
+			false,
+		},
+		{
+			"should refuse a body over the maximum size",
+			Opts().BaseUrl(server.URL).MaxResponseBytes(4),
+			Req(),
+			"",
+			true,
+		},
+		{
+			"should use the maximum size of the request",
+			Opts().BaseUrl(server.URL).MaxResponseBytes(4),
+			Req().MaxResponseBytes(-1),
+			`{"foo":"bar"}`,
+			false,
+		},
+		{
+			"should stream the body",
+			Opts().BaseUrl(server.URL),
+			Req().Stream(),
+			`{"foo":"bar"}`,
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.options).Get(context.Background(), "/", nil, tt.request)
+			assert.Nil(t, err)
+
+			b, err := got.Bytes()
+			assert.Equal(t, tt.want, string(b))
+			assert.Equal(t, tt.wantError, err != nil)
+		})
+	}
+}
+
+func TestDefaultClient_MultipleValues(t *testing.T) {
+	ctx := context.Background()
+
+	var tests = []struct {
+		name        string
+		options     *Options
+		query       *Q
+		request     *Request
+		wantQuery   url.Values
+		wantHeaders http.Header
+	}{
+		{
+			"should send multiple values",
+			Opts().BaseUrl("https://example.com"),
+			nil,
+			Req().
+				AddQuery("id", "1").
+				AddQuery("id", "2").
+				AddHeader("Accept", "application/json").
+				AddHeader("Accept", "text/plain"),
+			url.Values{"id": {"1", "2"}},
+			http.Header{"Accept": {"application/json", "text/plain"}},
+		},
+		{
+			"should replace the defaults key by key",
+			Opts().
+				BaseUrl("https://example.com").
+				AddDefaultQuery("id", "1").
+				AddDefaultQuery("id", "2").
+				WithDefaultQuery(Q{"page": "1"}).
+				AddDefaultQuery("sort", "name").
+				AddDefaultHeader("Accept", "text/plain").
+				WithDefaultHeader("X-Foo", "foo"),
+			&Q{"sort": "date"},
+			Req().
+				AddQuery("page", "2").
+				AddQuery("page", "3").
+				WithHeader("accept", "application/json"),
+			url.Values{"page": {"2", "3"}, "sort": {"date"}},
+			http.Header{"Accept": {"application/json"}, "X-Foo": {"foo"}},
+		},
+		{
+			"should remove the defaults",
+			Opts().
+				BaseUrl("https://example.com").
+				WithDefaultQuery(Q{"page": "1", "api_key": "secret"}).
+				WithDefaultHeader("X-Foo", "foo").
+				WithDefaultHeader("X-Bar", "bar"),
+			nil,
+			Req().
+				WithoutQuery("api_key").
+				WithoutHeader("x-foo"),
+			url.Values{"page": {"1"}},
+			http.Header{"X-Bar": {"bar"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goHttpClientMock := mocks.NewGoHttpClient(t)
+			goHttpClientMock.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+				return assert.ObjectsAreEqual(tt.wantQuery, req.URL.Query()) &&
+					assert.ObjectsAreEqual(tt.wantHeaders, req.Header)
+			})).Return(&http.Response{}, nil)
+
+			c := newClient(tt.options)
+			c.client = goHttpClientMock
+			_, err := c.Get(ctx, "/foo", tt.query, tt.request)
+
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestDefaultClient_Methods(t *testing.T) {
+	ctx := context.Background()
+
+	var tests = []struct {
+		name       string
+		call       func(c *defaultClient) (*Response, error)
+		wantMethod string
+		wantUrl    string
+		wantBody   string
+	}{
+		{
+			"head",
+			func(c *defaultClient) (*Response, error) {
+				return c.Head(ctx, "/foo", &Q{"foo": "bar"})
+			},
+			http.MethodHead,
+			"https://example.com/foo?foo=bar",
+			"",
+		},
+		{
+			"options",
+			func(c *defaultClient) (*Response, error) {
+				return c.Options(ctx, "/foo")
+			},
+			http.MethodOptions,
+			"https://example.com/foo",
+			"",
+		},
+		{
+			"trace",
+			func(c *defaultClient) (*Response, error) {
+				return c.Trace(ctx, "/foo")
+			},
+			http.MethodTrace,
+			"https://example.com/foo",
+			"",
+		},
+		{
+			"custom method",
+			func(c *defaultClient) (*Response, error) {
+				return c.Do(ctx, "PROPFIND", "/dav", &Q{"foo": "bar"}, strings.NewReader("<propfind/>"))
+			},
+			"PROPFIND",
+			"https://example.com/dav?foo=bar",
+			"<propfind/>",
+		},
+		{
+			"get with a body",
+			func(c *defaultClient) (*Response, error) {
+				return c.Get(ctx, "/search", nil, Req().Body(strings.NewReader(`{"q":"foo"}`)))
+			},
+			http.MethodGet,
+			"https://example.com/search",
+			`{"q":"foo"}`,
+		},
+		{
+			"delete with a body and a query",
+			func(c *defaultClient) (*Response, error) {
+				return c.Delete(ctx, "/users", Req().Query(Q{"force": "true"}).Body(strings.NewReader(`[1,2]`)))
+			},
+			http.MethodDelete,
+			"https://example.com/users?force=true",
+			`[1,2]`,
+		},
+		{
+			"send",
+			func(c *defaultClient) (*Response, error) {
+				return c.Send(ctx, Req().Method("PURGE").Endpoint("/cache/{key}").Path("key", "a b"))
+			},
+			"PURGE",
+			"https://example.com/cache/a%20b",
+			"",
+		},
+		{
+			"send defaults to get",
+			func(c *defaultClient) (*Response, error) {
+				return c.Send(ctx, Req().Endpoint("/foo"))
+			},
+			http.MethodGet,
+			"https://example.com/foo",
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goHttpClientMock := mocks.NewGoHttpClient(t)
+			goHttpClientMock.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+				body := ""
+				if req.Body != nil {
+					b, _ := io.ReadAll(req.Body)
+					body = string(b)
+				}
+
+				return req.Method == tt.wantMethod &&
+					req.URL.String() == tt.wantUrl &&
+					body == tt.wantBody
+			})).Return(&http.Response{}, nil)
+
+			c := newClient(Opts().BaseUrl("https://example.com"))
+			c.client = goHttpClientMock
+
+			_, err := tt.call(c)
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestDefaultClient_SendNil(t *testing.T) {
+	c := New(Opts().BaseUrl("https://example.com"))
+
+	res, err := c.Send(context.Background(), nil)
+	assert.Nil(t, res)
+	assert.Equal(t, ErrNilRequest, err)
+}
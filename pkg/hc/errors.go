@@ -0,0 +1,98 @@
+package hc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxErrorBodySize is the maximum number of bytes of the response body kept in an HTTPError
+const maxErrorBodySize = 4 << 10
+
+// HTTPError is returned for non successful responses when ErrorOnStatus is enabled, use errors.As to retrieve it
+type HTTPError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	Header     http.Header
+
+	// Body holds at most the first 4KB of the response body
+	Body []byte
+
+	// Problem is set when the response is an application/problem+json document
+	Problem *Problem
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("hc: %s %s returned %s", e.Method, e.URL, e.Status)
+}
+
+// newHTTPError builds the error from the response. The body is buffered, even in streaming mode, so that it's closed
+// and the connection released when the caller only looks at the error, and it can still be read from the response.
+func newHTTPError(req *http.Request, res *Response) *HTTPError {
+	hr := res.response
+	status := hr.Status
+	if status == "" {
+		status = fmt.Sprintf("%d %s", hr.StatusCode, http.StatusText(hr.StatusCode))
+	}
+
+	e := &HTTPError{
+		StatusCode: hr.StatusCode,
+		Status:     status,
+		Header:     hr.Header,
+	}
+
+	if req != nil {
+		e.Method = req.Method
+		e.URL = req.URL.Redacted()
+	}
+
+	if hr.Body != nil {
+		b, _ := res.buffer()
+		// the problem is parsed from the whole body, only the snapshot is truncated
+		e.Problem, _ = parseProblem(hr.Header, b)
+		if len(b) > maxErrorBodySize {
+			b = b[:maxErrorBodySize]
+		}
+		e.Body = append([]byte(nil), b...)
+	}
+
+	return e
+}
+
+// ErrBodyTooLarge is returned when the response body exceeds the MaxResponseBytes limit, use errors.As with a
+// BodyTooLargeError to get the details
+var ErrBodyTooLarge = errors.New("hc: response body too large")
+
+// BodyTooLargeError is returned when the response body exceeds the MaxResponseBytes limit, it matches ErrBodyTooLarge
+type BodyTooLargeError struct {
+	Limit int64
+
+	// ContentLength is the size declared by the server, -1 when unknown
+	ContentLength int64
+}
+
+func (e *BodyTooLargeError) Error() string {
+	if e.ContentLength >= 0 {
+		return fmt.Sprintf("%s: %d bytes exceed the limit of %d bytes", ErrBodyTooLarge, e.ContentLength, e.Limit)
+	}
+
+	return fmt.Sprintf("%s: exceeds the limit of %d bytes", ErrBodyTooLarge, e.Limit)
+}
+
+func (e *BodyTooLargeError) Is(target error) bool {
+	return target == ErrBodyTooLarge
+}
+
+// isSuccess tells if the status code is in the 2xx range
+func isSuccess(code int) bool {
+	return code >= 200 && code < 300
+}
+
+// readCloser reads from a reader and closes a different closer, useful to replay part of a body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,103 @@
+// Package hcotel adapts OpenTelemetry to the tracing hooks of hc, it's a separate module so that hc has no
+// dependencies.
+package hcotel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/jacoz/go-http-client/pkg/hc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans created by hc
+const instrumentationName = "github.com/jacoz/go-http-client/pkg/hc"
+
+var (
+	_ hc.Tracer     = (*tracer)(nil)
+	_ hc.Propagator = (*propagator)(nil)
+)
+
+// Tracer creates client spans with the tracer provider, the global one when nil
+func Tracer(tp trace.TracerProvider) hc.Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+
+	return &tracer{tracer: tp.Tracer(instrumentationName)}
+}
+
+// Propagator injects the trace context with the propagator, the global one when nil, eg. W3C Trace Context and Baggage
+func Propagator(p propagation.TextMapPropagator) hc.Propagator {
+	if p == nil {
+		p = otel.GetTextMapPropagator()
+	}
+
+	return &propagator{propagator: p}
+}
+
+type tracer struct {
+	tracer trace.Tracer
+}
+
+func (t *tracer) Start(ctx context.Context, name string, attrs ...hc.Attr) (context.Context, hc.Span) {
+	ctx, span := t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes(attrs)...),
+	)
+
+	return ctx, &spanAdapter{span: span}
+}
+
+type spanAdapter struct {
+	span trace.Span
+}
+
+func (s *spanAdapter) SetAttributes(attrs ...hc.Attr) {
+	s.span.SetAttributes(attributes(attrs)...)
+}
+
+func (s *spanAdapter) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+
+	s.span.End()
+}
+
+type propagator struct {
+	propagator propagation.TextMapPropagator
+}
+
+func (p *propagator) Inject(ctx context.Context, h http.Header) {
+	p.propagator.Inject(ctx, propagation.HeaderCarrier(h))
+}
+
+// attributes converts the attributes of hc, the values of unknown types are formatted as strings
+func attributes(attrs []hc.Attr) []attribute.KeyValue {
+	res := make([]attribute.KeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		switch v := a.Value.(type) {
+		case string:
+			res = append(res, attribute.String(a.Key, v))
+		case int:
+			res = append(res, attribute.Int(a.Key, v))
+		case int64:
+			res = append(res, attribute.Int64(a.Key, v))
+		case bool:
+			res = append(res, attribute.Bool(a.Key, v))
+		case float64:
+			res = append(res, attribute.Float64(a.Key, v))
+		default:
+			res = append(res, attribute.String(a.Key, fmt.Sprint(v)))
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,58 @@
+package hcotel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jacoz/go-http-client/pkg/hc"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestClient(t *testing.T) {
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("Traceparent")
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	c := hc.New(hc.Opts().
+		BaseUrl(server.URL).
+		WithTracer(Tracer(tp)).
+		WithPropagator(Propagator(propagation.TraceContext{})))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	_, err := c.Get(ctx, "/users/{id}", nil, hc.Req().Path("id", "1"))
+	parent.End()
+
+	assert.Nil(t, err)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 3) {
+		attempt, request := spans[0], spans[1]
+
+		assert.Equal(t, "GET /users/{id}", request.Name())
+		assert.Equal(t, trace.SpanKindClient, request.SpanKind())
+		assert.Equal(t, parent.SpanContext().SpanID(), request.Parent().SpanID())
+		assert.Equal(t, codes.Error, request.Status().Code)
+		assert.Contains(t, request.Attributes(), attribute.String("http.route", "/users/{id}"))
+		assert.Contains(t, request.Attributes(), attribute.Int("http.response.status_code", 500))
+
+		assert.Equal(t, request.SpanContext().SpanID(), attempt.Parent().SpanID())
+		assert.Contains(t, attempt.Attributes(), attribute.Int("http.request.resend_count", 0))
+
+		sc := attempt.SpanContext()
+		assert.Equal(t, "00-"+sc.TraceID().String()+"-"+sc.SpanID().String()+"-01", traceparent)
+	}
+}
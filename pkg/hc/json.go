@@ -0,0 +1,96 @@
+package hc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+const jsonContentType = "application/json"
+
+// GetJSON performs a GET request and decodes the json response into T.
+// Non 2xx responses are returned as *HTTPError, empty bodies leave T to its zero value.
+func GetJSON[T any](ctx context.Context, c Client, endpoint string, q *Q, r ...*Request) (T, *Response, error) {
+	res, err := c.Get(ctx, endpoint, q, jsonRequest(false, r...))
+	return decodeJSON[T](res, err)
+}
+
+// PostJSON encodes the body as json, performs a POST request and decodes the json response into Out
+func PostJSON[In, Out any](ctx context.Context, c Client, endpoint string, body In, r ...*Request) (Out, *Response, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		var v Out
+		return v, nil, err
+	}
+
+	res, err := c.Post(ctx, endpoint, bytes.NewReader(b), jsonRequest(true, r...))
+	return decodeJSON[Out](res, err)
+}
+
+// PatchJSON encodes the body as json, performs a PATCH request and decodes the json response into Out
+func PatchJSON[In, Out any](ctx context.Context, c Client, endpoint string, body In, r ...*Request) (Out, *Response, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		var v Out
+		return v, nil, err
+	}
+
+	res, err := c.Patch(ctx, endpoint, bytes.NewReader(b), jsonRequest(true, r...))
+	return decodeJSON[Out](res, err)
+}
+
+// PutJSON encodes the body as json, performs a PUT request and decodes the json response into Out
+func PutJSON[In, Out any](ctx context.Context, c Client, endpoint string, body In, r ...*Request) (Out, *Response, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		var v Out
+		return v, nil, err
+	}
+
+	res, err := c.Put(ctx, endpoint, bytes.NewReader(b), jsonRequest(true, r...))
+	return decodeJSON[Out](res, err)
+}
+
+// DeleteJSON performs a DELETE request and decodes the json response into T
+func DeleteJSON[T any](ctx context.Context, c Client, endpoint string, r ...*Request) (T, *Response, error) {
+	res, err := c.Delete(ctx, endpoint, jsonRequest(false, r...))
+	return decodeJSON[T](res, err)
+}
+
+// jsonRequest copies the request configuration adding the json Accept header, and the Content-Type one for the
+// requests with a body, if not already set
+func jsonRequest(body bool, r ...*Request) *Request {
+	req := Req()
+	if len(r) > 0 && r[0] != nil {
+		req = r[0].clone()
+	}
+
+	if body && req.headers.Get("Content-Type") == "" {
+		req.WithJsonContentType()
+	}
+	if req.headers.Get("Accept") == "" {
+		req.WithHeader("Accept", jsonContentType)
+	}
+
+	return req
+}
+
+func decodeJSON[T any](res *Response, err error) (T, *Response, error) {
+	var v T
+	if err != nil {
+		return v, res, err
+	}
+
+	// the error buffers and closes the body, so that the connection is released when only the error is checked
+	if !isSuccess(res.StatusCode()) {
+		return v, res, newHTTPError(res.response.Request, res)
+	}
+
+	if err := res.UnmarshalJson(&v); err != nil && !errors.Is(err, io.EOF) {
+		return v, res, err
+	}
+
+	return v, res, nil
+}
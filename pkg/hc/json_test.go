@@ -0,0 +1,195 @@
+package hc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jacoz/go-http-client/pkg/hc/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type user struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func jsonResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestGetJSON(t *testing.T) {
+	ctx := context.Background()
+
+	var tests = []struct {
+		name       string
+		response   *http.Response
+		err        error
+		want       user
+		wantStatus int
+		wantError  bool
+	}{
+		{
+			"should decode the response",
+			jsonResponse(200, `{"id":1,"name":"foo"}`),
+			nil,
+			user{ID: 1, Name: "foo"},
+			200,
+			false,
+		},
+		{
+			"should accept empty bodies",
+			jsonResponse(204, ``),
+			nil,
+			user{},
+			204,
+			false,
+		},
+		{
+			"should return an http error",
+			jsonResponse(404, `{}`),
+			nil,
+			user{},
+			404,
+			true,
+		},
+		{
+			"should return a decoding error",
+			jsonResponse(200, `{"id":"foo"}`),
+			nil,
+			user{},
+			200,
+			true,
+		},
+		{
+			"should return a transport error",
+			nil,
+			errors.New("foo"),
+			user{},
+			0,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goHttpClientMock := mocks.NewGoHttpClient(t)
+			goHttpClientMock.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+				return req.URL.String() == "https://example.com/users/1?foo=bar" &&
+					req.Header.Get("Accept") == "application/json" &&
+					req.Header.Get("Content-Type") == "" &&
+					req.Header.Get("X-Foo") == "foo"
+			})).Return(tt.response, tt.err)
+
+			c := newClient(Opts().BaseUrl("https://example.com"))
+			c.client = goHttpClientMock
+
+			r := Req().WithHeader("X-Foo", "foo")
+			got, res, err := GetJSON[user](ctx, c, "/users/1", &Q{"foo": "bar"}, r)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantError, err != nil)
+			if tt.wantStatus > 0 {
+				assert.Equal(t, tt.wantStatus, res.StatusCode())
+			}
+			assert.Equal(t, http.Header{"X-Foo": {"foo"}}, r.headers)
+		})
+	}
+
+	var httpErr *HTTPError
+	goHttpClientMock := mocks.NewGoHttpClient(t)
+	goHttpClientMock.On("Do", mock.Anything).Return(jsonResponse(500, `{}`), nil)
+
+	c := newClient(Opts().BaseUrl("https://example.com"))
+	c.client = goHttpClientMock
+	_, _, err := GetJSON[user](ctx, c, "/users/1", nil)
+
+	assert.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, 500, httpErr.StatusCode)
+}
+
+func TestPostJSON(t *testing.T) {
+	ctx := context.Background()
+
+	var tests = []struct {
+		name      string
+		body      any
+		want      user
+		wantError bool
+	}{
+		{
+			"should encode the body and decode the response",
+			user{Name: "foo"},
+			user{ID: 1, Name: "foo"},
+			false,
+		},
+		{
+			"should return an encoding error",
+			make(chan int),
+			user{},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goHttpClientMock := mocks.NewGoHttpClient(t)
+			goHttpClientMock.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+				b, _ := io.ReadAll(req.Body)
+
+				return req.Method == http.MethodPost &&
+					req.Header.Get("Content-Type") == "application/json" &&
+					string(b) == `{"id":0,"name":"foo"}`
+			})).Return(jsonResponse(201, `{"id":1,"name":"foo"}`), nil).Maybe()
+
+			c := newClient(Opts().BaseUrl("https://example.com"))
+			c.client = goHttpClientMock
+
+			got, _, err := PostJSON[any, user](ctx, c, "/users", tt.body)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantError, err != nil)
+		})
+	}
+}
+
+func TestDeleteJSON(t *testing.T) {
+	goHttpClientMock := mocks.NewGoHttpClient(t)
+	goHttpClientMock.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.Method == http.MethodDelete &&
+			req.Header.Get("Accept") == "application/json" &&
+			req.Header.Get("Content-Type") == ""
+	})).Return(jsonResponse(200, `{"id":1,"name":"foo"}`), nil)
+
+	c := newClient(Opts().BaseUrl("https://example.com"))
+	c.client = goHttpClientMock
+
+	got, _, err := DeleteJSON[user](context.Background(), c, "/users/1")
+	assert.Nil(t, err)
+	assert.Equal(t, user{ID: 1, Name: "foo"}, got)
+}
+
+func TestJSONHelpers_ReleaseBody(t *testing.T) {
+	ctx := context.Background()
+
+	for _, status := range []int{200, 404} {
+		body := &closeRecorder{Reader: strings.NewReader(`{"id":1}`)}
+		goHttpClientMock := mocks.NewGoHttpClient(t)
+		goHttpClientMock.On("Do", mock.Anything).Return(&http.Response{StatusCode: status, Body: body}, nil)
+
+		c := newClient(Opts().BaseUrl("https://example.com"))
+		c.client = goHttpClientMock
+
+		_, _, err := PostJSON[user, user](ctx, c, "/users", user{Name: "foo"}, Req().Stream())
+		assert.Equal(t, status != 200, err != nil)
+		assert.True(t, body.closed, "status %d", status)
+	}
+}
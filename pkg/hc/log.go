@@ -0,0 +1,228 @@
+package hc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Level is the severity of a log event, the values match the ones of log/slog
+type Level int
+
+const (
+	LevelDebug Level = -4
+	LevelInfo  Level = 0
+	LevelWarn  Level = 4
+	LevelError Level = 8
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	}
+
+	return "UNKNOWN"
+}
+
+// Attr is a key value pair of a log event
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// Logger receives the structured events of the client, it can be adapted to log/slog or any other logger
+type Logger interface {
+	Log(ctx context.Context, level Level, msg string, attrs ...Attr)
+}
+
+// LoggerFunc allows to use a function as a Logger
+type LoggerFunc func(ctx context.Context, level Level, msg string, attrs ...Attr)
+
+func (f LoggerFunc) Log(ctx context.Context, level Level, msg string, attrs ...Attr) {
+	f(ctx, level, msg, attrs...)
+}
+
+// redacted replaces the values of the sensitive headers and query parameters
+const redacted = "REDACTED"
+
+var (
+	defaultRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+	defaultRedactedQuery   = []string{"access_token", "api_key", "apikey", "client_secret", "password", "secret", "token"}
+)
+
+// LogPolicy defines how the requests are logged, use Logging to create it
+type LogPolicy struct {
+	logger     Logger
+	level      Level
+	errorLevel Level
+	headers    bool
+	bodyLimit  int
+	redact     []string
+}
+
+// Logging creates a log policy, by default every attempt is logged at info level, or at error level for transport
+// errors and 5xx responses, without headers and bodies
+func Logging(l Logger) *LogPolicy {
+	return &LogPolicy{
+		logger:     l,
+		level:      LevelInfo,
+		errorLevel: LevelError,
+	}
+}
+
+// Level sets the level of the successful requests
+func (p *LogPolicy) Level(v Level) *LogPolicy {
+	p.level = v
+	return p
+}
+
+// ErrorLevel sets the level of the transport errors and 5xx responses
+func (p *LogPolicy) ErrorLevel(v Level) *LogPolicy {
+	p.errorLevel = v
+	return p
+}
+
+// WithHeaders logs the request and response headers
+func (p *LogPolicy) WithHeaders() *LogPolicy {
+	p.headers = true
+	return p
+}
+
+// WithBody logs up to limit bytes of the request and response bodies, the bodies are left untouched
+func (p *LogPolicy) WithBody(limit int) *LogPolicy {
+	p.bodyLimit = limit
+	return p
+}
+
+// Redact hides the values of the given headers and query parameters, in addition to the credentials and the common
+// token parameters which are always hidden
+func (p *LogPolicy) Redact(keys ...string) *LogPolicy {
+	p.redact = append(p.redact, keys...)
+	return p
+}
+
+// Middleware returns the policy as a middleware, the client places it after all the others so that every attempt
+// is logged as it's sent
+func (p *LogPolicy) Middleware() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return p.do(next, req)
+		})
+	}
+}
+
+func (p *LogPolicy) do(client Doer, req *http.Request) (*http.Response, error) {
+	attrs := []Attr{
+		{"method", req.Method},
+		{"url", p.redactUrl(req.URL)},
+		{"endpoint", EndpointTemplate(req)},
+		{"attempt", Attempt(req)},
+		{"request_bytes", req.ContentLength},
+	}
+	if p.headers {
+		attrs = append(attrs, Attr{"request_headers", p.redactHeader(req.Header)})
+	}
+	if p.bodyLimit > 0 && req.GetBody != nil && req.Header.Get("Content-Encoding") == "" {
+		if body, err := req.GetBody(); err == nil {
+			b, _ := io.ReadAll(io.LimitReader(body, int64(p.bodyLimit)))
+			body.Close()
+			attrs = append(attrs, Attr{"request_body", string(b)})
+		}
+	}
+
+	start := time.Now()
+	res, err := client.Do(req)
+	attrs = append(attrs, Attr{"duration", time.Since(start)})
+
+	level := p.level
+	if err != nil {
+		level = p.errorLevel
+		attrs = append(attrs, Attr{"error", p.redactError(err)})
+	} else {
+		if res.StatusCode >= 500 {
+			level = p.errorLevel
+		}
+
+		attrs = append(attrs, Attr{"status", res.StatusCode}, Attr{"response_bytes", res.ContentLength})
+		if p.headers {
+			attrs = append(attrs, Attr{"response_headers", p.redactHeader(res.Header)})
+		}
+		// encoded bodies are decoded only later by the client, so they wouldn't be readable
+		if p.bodyLimit > 0 && res.Body != nil && res.Body != http.NoBody && res.Header.Get("Content-Encoding") == "" {
+			b, _ := io.ReadAll(io.LimitReader(res.Body, int64(p.bodyLimit)))
+			res.Body = readCloser{io.MultiReader(bytes.NewReader(b), res.Body), res.Body}
+			attrs = append(attrs, Attr{"response_body", string(b)})
+		}
+	}
+
+	p.logger.Log(req.Context(), level, "hc: request", attrs...)
+
+	return res, err
+}
+
+func (p *LogPolicy) redacts(key string, defaults []string) bool {
+	for _, k := range defaults {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	for _, k := range p.redact {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *LogPolicy) redactHeader(h http.Header) http.Header {
+	res := h.Clone()
+	for k := range res {
+		if p.redacts(k, defaultRedactedHeaders) {
+			res[k] = []string{redacted}
+		}
+	}
+
+	return res
+}
+
+// redactError hides the sensitive query parameters of the url the transport errors contain
+func (p *LogPolicy) redactError(err error) string {
+	var e *url.Error
+	if !errors.As(err, &e) {
+		return err.Error()
+	}
+
+	u, perr := url.Parse(e.URL)
+	if perr != nil {
+		return err.Error()
+	}
+
+	return strings.ReplaceAll(err.Error(), e.URL, p.redactUrl(u))
+}
+
+func (p *LogPolicy) redactUrl(u *url.URL) string {
+	q := u.Query()
+	for k := range q {
+		if p.redacts(k, defaultRedactedQuery) {
+			q[k] = []string{redacted}
+		}
+	}
+
+	res := *u
+	res.RawQuery = q.Encode()
+
+	return res.Redacted()
+}
@@ -0,0 +1,281 @@
+package hc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricLabels identifies a series of metrics
+type MetricLabels struct {
+	Method string
+	Host   string
+
+	// Endpoint is the template of the request to keep the cardinality low, empty when the request has none
+	Endpoint string
+
+	// StatusClass is the class of the status code, eg. 2xx, empty when the request failed without a response
+	StatusClass string
+
+	// ErrorKind classifies the transport errors: timeout, canceled, dns, tls, connection or other
+	ErrorKind string
+}
+
+// Metrics receives the outcome of every attempt of the requests, register it with Options.WithMetrics
+type Metrics interface {
+	IncRequest(labels MetricLabels)
+	ObserveLatency(labels MetricLabels, d time.Duration)
+}
+
+// metricsMiddleware records every attempt that goes through it
+func metricsMiddleware(m Metrics) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			res, err := next.Do(req)
+
+			// the paths of the requests without a template would make a series for every id
+			endpoint, _ := endpointTemplate(req)
+			labels := MetricLabels{
+				Method:    req.Method,
+				Host:      req.URL.Host,
+				Endpoint:  endpoint,
+				ErrorKind: errorKind(err),
+			}
+			if res != nil {
+				labels.StatusClass = fmt.Sprintf("%dxx", res.StatusCode/100)
+			}
+
+			m.IncRequest(labels)
+			m.ObserveLatency(labels, time.Since(start))
+
+			return res, err
+		})
+	}
+}
+
+// errorKind classifies a transport error
+func errorKind(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var (
+		netErr     net.Error
+		dnsErr     *net.DNSError
+		opErr      *net.OpError
+		recordErr  tls.RecordHeaderError
+		authErr    x509.UnknownAuthorityError
+		hostErr    x509.HostnameError
+		invalidErr x509.CertificateInvalidError
+	)
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.As(err, &dnsErr):
+		return "dns"
+	case errors.As(err, &recordErr), errors.As(err, &authErr), errors.As(err, &hostErr), errors.As(err, &invalidErr):
+		return "tls"
+	case errors.As(err, &opErr):
+		return "connection"
+	}
+
+	return "other"
+}
+
+// DefaultLatencyBuckets are the upper bounds of the latency histograms, the same as the Prometheus default ones
+var DefaultLatencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// MetricSeries is the snapshot of the metrics of a set of labels
+type MetricSeries struct {
+	Labels   MetricLabels
+	Requests uint64
+	Latency  LatencyHistogram
+}
+
+// LatencyHistogram counts the latencies below each bound, the counts are cumulative as in Prometheus
+type LatencyHistogram struct {
+	Bounds []time.Duration
+	Counts []uint64
+	Count  uint64
+	Sum    time.Duration
+}
+
+// InMemoryMetrics keeps the metrics in memory, it can be published with expvar.Publish and exposed in the Prometheus
+// text format with WritePrometheus
+type InMemoryMetrics struct {
+	mu      sync.Mutex
+	buckets []time.Duration
+	series  map[MetricLabels]*MetricSeries
+}
+
+var _ Metrics = (*InMemoryMetrics)(nil)
+
+// NewInMemoryMetrics creates an in memory metrics registry with the given latency buckets, DefaultLatencyBuckets
+// when none is given
+func NewInMemoryMetrics(buckets ...time.Duration) *InMemoryMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+
+	b := append([]time.Duration(nil), buckets...)
+	sort.Slice(b, func(i, j int) bool { return b[i] < b[j] })
+
+	return &InMemoryMetrics{
+		buckets: b,
+		series:  map[MetricLabels]*MetricSeries{},
+	}
+}
+
+func (m *InMemoryMetrics) IncRequest(labels MetricLabels) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.get(labels).Requests++
+}
+
+func (m *InMemoryMetrics) ObserveLatency(labels MetricLabels, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h := &m.get(labels).Latency
+	for i, b := range h.Bounds {
+		if d <= b {
+			h.Counts[i]++
+		}
+	}
+	h.Count++
+	h.Sum += d
+}
+
+// get returns the series of the labels, creating it if needed, the lock must be held
+func (m *InMemoryMetrics) get(labels MetricLabels) *MetricSeries {
+	s, ok := m.series[labels]
+	if !ok {
+		s = &MetricSeries{
+			Labels: labels,
+			Latency: LatencyHistogram{
+				Bounds: m.buckets,
+				Counts: make([]uint64, len(m.buckets)),
+			},
+		}
+		m.series[labels] = s
+	}
+
+	return s
+}
+
+// Snapshot returns a copy of all the series, sorted by labels
+func (m *InMemoryMetrics) Snapshot() []MetricSeries {
+	m.mu.Lock()
+	res := make([]MetricSeries, 0, len(m.series))
+	for _, s := range m.series {
+		c := *s
+		c.Latency.Counts = append([]uint64(nil), s.Latency.Counts...)
+		res = append(res, c)
+	}
+	m.mu.Unlock()
+
+	sort.Slice(res, func(i, j int) bool {
+		a, b := res[i].Labels, res[j].Labels
+		for _, v := range [][2]string{
+			{a.Method, b.Method},
+			{a.Host, b.Host},
+			{a.Endpoint, b.Endpoint},
+			{a.StatusClass, b.StatusClass},
+			{a.ErrorKind, b.ErrorKind},
+		} {
+			if v[0] != v[1] {
+				return v[0] < v[1]
+			}
+		}
+		return false
+	})
+
+	return res
+}
+
+// String returns the snapshot as json, so that the metrics can be published with expvar.Publish
+func (m *InMemoryMetrics) String() string {
+	b, err := json.Marshal(m.Snapshot())
+	if err != nil {
+		return "null"
+	}
+
+	return string(b)
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format, as hc_requests_total and
+// hc_request_duration_seconds
+func (m *InMemoryMetrics) WritePrometheus(w io.Writer) error {
+	series := m.Snapshot()
+
+	var b strings.Builder
+	b.WriteString("# HELP hc_requests_total Number of http requests sent, including the retries.\n")
+	b.WriteString("# TYPE hc_requests_total counter\n")
+	for _, s := range series {
+		fmt.Fprintf(&b, "hc_requests_total{%s} %d\n", promLabels(s.Labels), s.Requests)
+	}
+
+	b.WriteString("# HELP hc_request_duration_seconds Latency of the http requests.\n")
+	b.WriteString("# TYPE hc_request_duration_seconds histogram\n")
+	for _, s := range series {
+		labels := promLabels(s.Labels)
+		for i, bound := range s.Latency.Bounds {
+			le := strconv.FormatFloat(bound.Seconds(), 'g', -1, 64)
+			fmt.Fprintf(&b, "hc_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, le, s.Latency.Counts[i])
+		}
+		fmt.Fprintf(&b, "hc_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, s.Latency.Count)
+		fmt.Fprintf(&b, "hc_request_duration_seconds_sum{%s} %s\n", labels, strconv.FormatFloat(s.Latency.Sum.Seconds(), 'g', -1, 64))
+		fmt.Fprintf(&b, "hc_request_duration_seconds_count{%s} %d\n", labels, s.Latency.Count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// PrometheusHandler serves the metrics in the Prometheus text exposition format
+func PrometheusHandler(m *InMemoryMetrics) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.WritePrometheus(w)
+	})
+}
+
+var promEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func promLabels(l MetricLabels) string {
+	return fmt.Sprintf(`method="%s",host="%s",endpoint="%s",status_class="%s",error_kind="%s"`,
+		promEscaper.Replace(l.Method),
+		promEscaper.Replace(l.Host),
+		promEscaper.Replace(l.Endpoint),
+		promEscaper.Replace(l.StatusClass),
+		promEscaper.Replace(l.ErrorKind),
+	)
+}
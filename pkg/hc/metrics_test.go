@@ -0,0 +1,156 @@
+package hc
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacoz/go-http-client/pkg/hc/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestErrorKind(t *testing.T) {
+	var tests = []struct {
+		name  string
+		input error
+		want  string
+	}{
+		{"no error", nil, ""},
+		{"canceled", fmt.Errorf("get: %w", context.Canceled), "canceled"},
+		{"deadline", fmt.Errorf("get: %w", context.DeadlineExceeded), "timeout"},
+		{"net timeout", &net.OpError{Op: "dial", Err: timeoutError{}}, "timeout"},
+		{"dns", &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host"}}, "dns"},
+		{"tls", fmt.Errorf("get: %w", x509.UnknownAuthorityError{}), "tls"},
+		{"connection", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, "connection"},
+		{"other", errors.New("foo"), "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, errorKind(tt.input))
+		})
+	}
+}
+
+func TestInMemoryMetrics(t *testing.T) {
+	m := NewInMemoryMetrics(100*time.Millisecond, 10*time.Millisecond)
+	labels := MetricLabels{Method: "GET", Host: "example.com", Endpoint: "/users/{id}", StatusClass: "2xx"}
+
+	m.IncRequest(labels)
+	m.ObserveLatency(labels, 5*time.Millisecond)
+	m.IncRequest(labels)
+	m.ObserveLatency(labels, 50*time.Millisecond)
+	m.IncRequest(MetricLabels{Method: "GET", Host: "example.com", Endpoint: "/", ErrorKind: "timeout"})
+
+	got := m.Snapshot()
+	if assert.Len(t, got, 2) {
+		assert.Equal(t, "/", got[0].Labels.Endpoint)
+		assert.Equal(t, uint64(1), got[0].Requests)
+
+		assert.Equal(t, MetricSeries{
+			Labels:   labels,
+			Requests: 2,
+			Latency: LatencyHistogram{
+				Bounds: []time.Duration{10 * time.Millisecond, 100 * time.Millisecond},
+				Counts: []uint64{1, 2},
+				Count:  2,
+				Sum:    55 * time.Millisecond,
+			},
+		}, got[1])
+	}
+}
+
+func TestInMemoryMetrics_WritePrometheus(t *testing.T) {
+	m := NewInMemoryMetrics(100 * time.Millisecond)
+	labels := MetricLabels{Method: "GET", Host: "example.com", Endpoint: `/a"b`, StatusClass: "2xx"}
+	m.IncRequest(labels)
+	m.ObserveLatency(labels, 50*time.Millisecond)
+
+	buf := new(strings.Builder)
+	assert.Nil(t, m.WritePrometheus(buf))
+
+	l := `method="GET",host="example.com",endpoint="/a\"b",status_class="2xx",error_kind=""`
+	assert.Equal(t, `# HELP hc_requests_total Number of http requests sent, including the retries.
+# TYPE hc_requests_total counter
+hc_requests_total{`+l+`} 1
+# HELP hc_request_duration_seconds Latency of the http requests.
+# TYPE hc_request_duration_seconds histogram
+hc_request_duration_seconds_bucket{`+l+`,le="0.1"} 1
+hc_request_duration_seconds_bucket{`+l+`,le="+Inf"} 1
+hc_request_duration_seconds_sum{`+l+`} 0.05
+hc_request_duration_seconds_count{`+l+`} 1
+`, buf.String())
+
+	rec := httptest.NewRecorder()
+	PrometheusHandler(m).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, buf.String(), rec.Body.String())
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+}
+
+func TestInMemoryMetrics_Expvar(t *testing.T) {
+	m := NewInMemoryMetrics()
+	m.IncRequest(MetricLabels{Method: "GET"})
+
+	var v expvar.Var = m
+	assert.Contains(t, v.String(), `"Method":"GET"`)
+	assert.Contains(t, v.String(), `"Requests":1`)
+}
+
+func TestDefaultClient_Metrics(t *testing.T) {
+	goHttpClientMock := mocks.NewGoHttpClient(t)
+	goHttpClientMock.On("Do", mock.Anything).Return(nil, &net.OpError{Op: "dial", Err: errors.New("connection refused")}).Once()
+	goHttpClientMock.On("Do", mock.Anything).Return(&http.Response{StatusCode: 201}, nil).Once()
+
+	m := NewInMemoryMetrics()
+	c := newClient(Opts().BaseUrl("https://example.com").WithRetry(Retry().Backoff(ConstantBackoff(0))).WithMetrics(m))
+	c.client = goHttpClientMock
+
+	_, err := c.Put(context.Background(), "/users/{id}", nil, Req().Path("id", "1"))
+	assert.Nil(t, err)
+
+	got := m.Snapshot()
+	if assert.Len(t, got, 2) {
+		assert.Equal(t, MetricLabels{Method: "PUT", Host: "example.com", Endpoint: "/users/{id}", ErrorKind: "connection"}, got[0].Labels)
+		assert.Equal(t, MetricLabels{Method: "PUT", Host: "example.com", Endpoint: "/users/{id}", StatusClass: "2xx"}, got[1].Labels)
+		assert.Equal(t, uint64(1), got[1].Latency.Count)
+	}
+}
+
+func TestDefaultClient_MetricsWithoutTemplate(t *testing.T) {
+	goHttpClientMock := mocks.NewGoHttpClient(t)
+	goHttpClientMock.On("Do", mock.Anything).Return(&http.Response{StatusCode: 200}, nil)
+
+	m := NewInMemoryMetrics()
+	c := newClient(Opts().BaseUrl("https://example.com").WithMetrics(m))
+	c.client = goHttpClientMock
+
+	for _, id := range []string{"1", "2"} {
+		_, err := c.Get(context.Background(), "/users/"+id, nil)
+		assert.Nil(t, err)
+		_, err = c.Get(context.Background(), "/orders/"+id, nil, Req().Template("/orders/{id}"))
+		assert.Nil(t, err)
+	}
+
+	got := m.Snapshot()
+	if assert.Len(t, got, 2) {
+		assert.Equal(t, MetricLabels{Method: "GET", Host: "example.com", StatusClass: "2xx"}, got[0].Labels)
+		assert.Equal(t, uint64(2), got[0].Requests)
+		assert.Equal(t, MetricLabels{Method: "GET", Host: "example.com", Endpoint: "/orders/{id}", StatusClass: "2xx"}, got[1].Labels)
+		assert.Equal(t, uint64(2), got[1].Requests)
+	}
+}
@@ -0,0 +1,30 @@
+package hc
+
+import "net/http"
+
+type (
+	// Doer sends an http request and returns its response, *http.Client satisfies it
+	Doer interface {
+		Do(req *http.Request) (*http.Response, error)
+	}
+
+	// DoerFunc allows to use an ordinary function as a Doer
+	DoerFunc func(req *http.Request) (*http.Response, error)
+
+	// Middleware wraps a Doer to add behavior around every request, eg. logging, auth or signing
+	Middleware func(next Doer) Doer
+)
+
+// Do calls f(req)
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// chain wraps the Doer with the middlewares, the first middleware is the outermost one
+func chain(d Doer, mw ...Middleware) Doer {
+	for i := len(mw) - 1; i >= 0; i-- {
+		d = mw[i](d)
+	}
+
+	return d
+}
@@ -0,0 +1,100 @@
+package hc
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/jacoz/go-http-client/pkg/hc/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func recorder(name string, calls *[]string) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			*calls = append(*calls, name)
+			req.Header.Add("X-Chain", name)
+			return next.Do(req)
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	var calls []string
+
+	d := chain(DoerFunc(func(req *http.Request) (*http.Response, error) {
+		calls = append(calls, "doer")
+		return &http.Response{StatusCode: 200}, nil
+	}), recorder("first", &calls), recorder("second", &calls))
+
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	got, err := d.Do(req)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 200, got.StatusCode)
+	assert.Equal(t, []string{"first", "second", "doer"}, calls)
+}
+
+func TestDefaultClient_Middlewares(t *testing.T) {
+	ctx := context.Background()
+
+	var tests = []struct {
+		name      string
+		setup     func(calls *[]string) (*Options, *Request)
+		wantCalls []string
+		wantChain []string
+	}{
+		{
+			"without middlewares",
+			func(calls *[]string) (*Options, *Request) {
+				return Opts().BaseUrl("https://example.com"), Req()
+			},
+			nil,
+			nil,
+		},
+		{
+			"client and request middlewares",
+			func(calls *[]string) (*Options, *Request) {
+				return Opts().BaseUrl("https://example.com").Use(recorder("client", calls)),
+					Req().Use(recorder("request", calls))
+			},
+			[]string{"client", "request"},
+			[]string{"client", "request"},
+		},
+		{
+			"middlewares run for every attempt",
+			func(calls *[]string) (*Options, *Request) {
+				return Opts().
+					BaseUrl("https://example.com").
+					WithRetry(Retry().Backoff(ConstantBackoff(0))).
+					Use(recorder("client", calls)), Req()
+			},
+			[]string{"client", "client"},
+			[]string{"client"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			o, r := tt.setup(&calls)
+
+			goHttpClientMock := mocks.NewGoHttpClient(t)
+			if o.retry != nil {
+				goHttpClientMock.On("Do", mock.Anything).Return(&http.Response{StatusCode: 503}, nil).Once()
+			}
+			goHttpClientMock.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+				return assert.ObjectsAreEqual(tt.wantChain, req.Header.Values("X-Chain"))
+			})).Return(&http.Response{StatusCode: 200}, nil).Once()
+
+			c := newClient(o)
+			c.client = goHttpClientMock
+			got, err := c.Get(ctx, "/foo", nil, r)
+
+			assert.Nil(t, err)
+			assert.True(t, got.Ok())
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"net/http"
+	"net/textproto"
+	"net/url"
 )
 
 type (
@@ -14,16 +17,42 @@ type (
 	D map[string]interface{}
 )
 
+// values converts the query to url values
+func (q Q) values() url.Values {
+	v := url.Values{}
+	for k, val := range q {
+		v.Set(k, val)
+	}
+
+	return v
+}
+
+// jsonReader encodes the data, a marshalling error is returned by the reader instead of panicking
 func (d *D) jsonReader() io.Reader {
 	v, err := json.Marshal(d)
 	if err != nil {
-		panic(err)
+		return errorReader{err}
 	}
 
 	return bytes.NewReader(v)
 }
 
-// Json helper allows to easily map a request that internally is then converted to a reader with the json object
+// Json helper allows to easily map a request that internally is then converted to a reader with the json object.
+// Request.Body accepts D directly and reports the marshalling errors before sending the request.
 func Json(v D) io.Reader {
 	return v.jsonReader()
 }
+
+// mergeHeader copies the headers from src to dst, a key in src replaces all the values of the same key in dst
+func mergeHeader(dst, src http.Header) {
+	for k, v := range src {
+		dst[textproto.CanonicalMIMEHeaderKey(k)] = append([]string(nil), v...)
+	}
+}
+
+// mergeValues copies the values from src to dst, a key in src replaces all the values of the same key in dst
+func mergeValues(dst, src url.Values) {
+	for k, v := range src {
+		dst[k] = append([]string(nil), v...)
+	}
+}
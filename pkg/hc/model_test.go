@@ -34,3 +34,9 @@ func TestJson(t *testing.T) {
 		})
 	}
 }
+
+func TestJson_Error(t *testing.T) {
+	_, err := io.ReadAll(Json(D{"foo": func() {}}))
+
+	assert.NotNil(t, err)
+}
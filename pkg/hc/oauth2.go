@@ -0,0 +1,235 @@
+package hc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTokenResponseSize is the maximum number of bytes read from the token endpoint
+const maxTokenResponseSize = 1 << 20
+
+// ErrTokenRequest is returned when the token endpoint refuses a grant, use errors.As with an OAuth2Error to get the
+// details
+var ErrTokenRequest = errors.New("hc: token request failed")
+
+// OAuth2Error is the error response of the token endpoint, as defined by RFC 6749, it matches ErrTokenRequest
+type OAuth2Error struct {
+	StatusCode int
+
+	// Code is the error code, eg. invalid_client or invalid_grant
+	Code        string
+	Description string
+}
+
+func (e *OAuth2Error) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("%s: %s: %s", ErrTokenRequest, e.Code, e.Description)
+	}
+
+	return fmt.Sprintf("%s: %s", ErrTokenRequest, e.Code)
+}
+
+func (e *OAuth2Error) Is(target error) bool {
+	return target == ErrTokenRequest
+}
+
+// OAuth2Config authenticates the requests with the tokens of an OAuth2 token endpoint, use OAuth2 to create it
+type OAuth2Config struct {
+	tokenUrl     string
+	clientId     string
+	clientSecret string
+	scopes       []string
+	params       url.Values
+	inBody       bool
+	client       Doer
+
+	mu           sync.Mutex
+	refreshToken string
+	refreshOnly  bool
+
+	bearer *bearerAuth
+}
+
+var _ TokenSource = (*OAuth2Config)(nil)
+
+// OAuth2 creates an authenticator with the client credentials grant. The tokens are cached until shortly before they
+// expire, concurrent requests share the same token request and a request rejected with 401 is sent once more with a
+// fresh token. When the server returns a refresh token, it's used to renew the access token.
+func OAuth2(tokenUrl, clientId, clientSecret string) *OAuth2Config {
+	c := &OAuth2Config{
+		tokenUrl:     tokenUrl,
+		clientId:     clientId,
+		clientSecret: clientSecret,
+		params:       url.Values{},
+		client:       &http.Client{Timeout: tokenTimeout},
+	}
+	c.bearer = &bearerAuth{source: TokenSourceFunc(c.grant)}
+
+	return c
+}
+
+// Scopes sets the scopes requested with the client credentials grant
+func (c *OAuth2Config) Scopes(v ...string) *OAuth2Config {
+	c.scopes = v
+	return c
+}
+
+// Param adds a parameter to the token requests, eg. audience or resource
+func (c *OAuth2Config) Param(k, v string) *OAuth2Config {
+	c.params.Add(k, v)
+	return c
+}
+
+// RefreshToken switches to the refresh token grant, the tokens are obtained with the given refresh token only and the
+// rotated refresh tokens returned by the server replace it
+func (c *OAuth2Config) RefreshToken(v string) *OAuth2Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.refreshToken = v
+	c.refreshOnly = true
+	return c
+}
+
+// CredentialsInBody sends the client id and secret as form parameters instead of with basic auth, for the servers
+// which don't support the latter
+func (c *OAuth2Config) CredentialsInBody() *OAuth2Config {
+	c.inBody = true
+	return c
+}
+
+// WithClient sets the client of the token requests, by default a client with a 30 seconds timeout
+func (c *OAuth2Config) WithClient(v Doer) *OAuth2Config {
+	c.client = v
+	return c
+}
+
+// Token returns the cached token, requesting a new one if it's missing or about to expire
+func (c *OAuth2Config) Token(ctx context.Context) (*Token, error) {
+	return c.bearer.get(ctx)
+}
+
+func (c *OAuth2Config) Authenticate(req *http.Request) error {
+	return c.bearer.Authenticate(req)
+}
+
+// Reauthenticate discards the token rejected by the server and authenticates the request with a fresh one
+func (c *OAuth2Config) Reauthenticate(req *http.Request) error {
+	c.bearer.invalidate(req.Header.Get("Authorization"))
+	return c.bearer.Authenticate(req)
+}
+
+// grant requests a new token, with the refresh token when there is one. In the client credentials flow a refused
+// refresh token is dropped and the client credentials are used instead.
+func (c *OAuth2Config) grant(ctx context.Context) (*Token, error) {
+	c.mu.Lock()
+	refreshToken, refreshOnly := c.refreshToken, c.refreshOnly
+	c.mu.Unlock()
+
+	if refreshToken != "" {
+		t, err := c.request(ctx, url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {refreshToken},
+		})
+		if err == nil || refreshOnly || !errors.Is(err, ErrTokenRequest) {
+			return t, err
+		}
+
+		c.mu.Lock()
+		c.refreshToken = ""
+		c.mu.Unlock()
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(c.scopes) > 0 {
+		form.Set("scope", strings.Join(c.scopes, " "))
+	}
+
+	return c.request(ctx, form)
+}
+
+// tokenResponse is the successful response of the token endpoint, some servers send expires_in as a string
+type tokenResponse struct {
+	AccessToken  string      `json:"access_token"`
+	TokenType    string      `json:"token_type"`
+	ExpiresIn    json.Number `json:"expires_in"`
+	RefreshToken string      `json:"refresh_token"`
+}
+
+type errorResponse struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// request sends a token request, storing the refresh token of the response
+func (c *OAuth2Config) request(ctx context.Context, form url.Values) (*Token, error) {
+	for k, v := range c.params {
+		form[k] = v
+	}
+	if c.inBody {
+		form.Set("client_id", c.clientId)
+		if c.clientSecret != "" {
+			form.Set("client_secret", c.clientSecret)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.tokenUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if !c.inBody {
+		// the credentials are form encoded before being used for basic auth, as required by RFC 6749
+		req.SetBasicAuth(url.QueryEscape(c.clientId), url.QueryEscape(c.clientSecret))
+	}
+
+	start := time.Now()
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(io.LimitReader(res.Body, maxTokenResponseSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		e := &OAuth2Error{StatusCode: res.StatusCode}
+		var v errorResponse
+		if json.Unmarshal(b, &v) == nil && v.Code != "" {
+			e.Code, e.Description = v.Code, v.Description
+		} else {
+			e.Code = http.StatusText(res.StatusCode)
+		}
+		return nil, e
+	}
+
+	var v tokenResponse
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrTokenRequest, err)
+	}
+
+	t := &Token{AccessToken: v.AccessToken, TokenType: v.TokenType}
+	if s, err := v.ExpiresIn.Int64(); err == nil && s > 0 {
+		t.Expiry = start.Add(time.Duration(s) * time.Second)
+	}
+
+	if v.RefreshToken != "" {
+		c.mu.Lock()
+		c.refreshToken = v.RefreshToken
+		c.mu.Unlock()
+	}
+
+	return t, nil
+}
@@ -0,0 +1,267 @@
+package hc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// tokenServer is a fake token endpoint, every grant returns a new access token and the responses can be overridden
+type tokenServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	forms     []url.Values
+	auths     []string
+	expiresIn any
+	refresh   string
+	refuse    map[string]string
+}
+
+func newTokenServer(t *testing.T) *tokenServer {
+	s := &tokenServer{expiresIn: 3600, refuse: map[string]string{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		r.ParseForm()
+		s.forms = append(s.forms, r.PostForm)
+		s.auths = append(s.auths, r.Header.Get("Authorization"))
+
+		w.Header().Set("Content-Type", "application/json")
+		if code, ok := s.refuse[r.PostForm.Get("grant_type")]; ok {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"error": %q, "error_description": "refused"}`, code)
+			return
+		}
+
+		fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "bearer", "expires_in": %#v, "refresh_token": %q}`,
+			len(s.forms), s.expiresIn, s.refresh)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *tokenServer) grants() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var res []string
+	for _, f := range s.forms {
+		res = append(res, f.Get("grant_type"))
+	}
+
+	return res
+}
+
+func TestOAuth2_ClientCredentials(t *testing.T) {
+	s := newTokenServer(t)
+	auth := OAuth2(s.URL, "client:id", "secret").Scopes("read", "write").Param("audience", "api")
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+		assert.Nil(t, auth.Authenticate(req))
+		assert.Equal(t, "Bearer token-1", req.Header.Get("Authorization"))
+	}
+
+	assert.Equal(t, []url.Values{{
+		"grant_type": {"client_credentials"},
+		"scope":      {"read write"},
+		"audience":   {"api"},
+	}}, s.forms)
+	assert.Equal(t, []string{"Basic Y2xpZW50JTNBaWQ6c2VjcmV0"}, s.auths)
+
+	tok, err := auth.Token(context.Background())
+	assert.Nil(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), tok.Expiry, time.Minute)
+}
+
+func TestOAuth2_CredentialsInBody(t *testing.T) {
+	s := newTokenServer(t)
+	s.expiresIn = "3600"
+
+	tok, err := OAuth2(s.URL, "id", "secret").CredentialsInBody().Token(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "token-1", tok.AccessToken)
+	assert.False(t, tok.Expiry.IsZero())
+
+	assert.Equal(t, []url.Values{{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {"id"},
+		"client_secret": {"secret"},
+	}}, s.forms)
+	assert.Equal(t, []string{""}, s.auths)
+}
+
+func TestOAuth2_RefreshToken(t *testing.T) {
+	s := newTokenServer(t)
+	s.expiresIn = 1 // always about to expire, so every call requests a new token
+	s.refresh = "rotated"
+
+	auth := OAuth2(s.URL, "id", "").RefreshToken("initial")
+	for _, want := range []string{"token-1", "token-2"} {
+		tok, err := auth.Token(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, want, tok.AccessToken)
+	}
+
+	assert.Equal(t, []string{"refresh_token", "refresh_token"}, s.grants())
+	assert.Equal(t, "initial", s.forms[0].Get("refresh_token"))
+	assert.Equal(t, "rotated", s.forms[1].Get("refresh_token"))
+
+	// without client credentials to fall back to, a refused refresh token is an error
+	s.refuse["refresh_token"] = "invalid_grant"
+	_, err := auth.Token(context.Background())
+
+	var e *OAuth2Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, &OAuth2Error{StatusCode: http.StatusBadRequest, Code: "invalid_grant", Description: "refused"}, e)
+	assert.True(t, errors.Is(err, ErrTokenRequest))
+	assert.EqualError(t, err, "hc: token request failed: invalid_grant: refused")
+}
+
+func TestOAuth2_RefreshFallback(t *testing.T) {
+	s := newTokenServer(t)
+	s.expiresIn = 1
+	s.refresh = "refresh"
+
+	auth := OAuth2(s.URL, "id", "secret")
+	for i := 0; i < 2; i++ {
+		_, err := auth.Token(context.Background())
+		assert.Nil(t, err)
+	}
+
+	s.refuse["refresh_token"] = "invalid_grant"
+	tok, err := auth.Token(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "token-4", tok.AccessToken)
+
+	assert.Equal(t, []string{"client_credentials", "refresh_token", "refresh_token", "client_credentials"}, s.grants())
+}
+
+func TestOAuth2_Errors(t *testing.T) {
+	s := newTokenServer(t)
+	s.refuse["client_credentials"] = "invalid_client"
+
+	_, err := OAuth2(s.URL, "id", "secret").Token(context.Background())
+	assert.EqualError(t, err, "hc: token request failed: invalid_client: refused")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/unavailable" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, "<html>")
+	}))
+	defer srv.Close()
+
+	_, err = OAuth2(srv.URL+"/unavailable", "id", "secret").Token(context.Background())
+	assert.Equal(t, &OAuth2Error{StatusCode: http.StatusServiceUnavailable, Code: "Service Unavailable"}, err)
+
+	_, err = OAuth2(srv.URL, "id", "secret").Token(context.Background())
+	assert.True(t, errors.Is(err, ErrTokenRequest))
+}
+
+func TestDefaultClient_OAuth2(t *testing.T) {
+	var tests = []struct {
+		name       string
+		accepted   string
+		wantStatus int
+		wantTokens []string
+	}{
+		{
+			"valid token",
+			"token-1",
+			http.StatusOK,
+			[]string{"Bearer token-1"},
+		},
+		{
+			"retried with a fresh token",
+			"token-2",
+			http.StatusOK,
+			[]string{"Bearer token-1", "Bearer token-2"},
+		},
+		{
+			"retried only once",
+			"token-3",
+			http.StatusUnauthorized,
+			[]string{"Bearer token-1", "Bearer token-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTokenServer(t)
+
+			var tokens []string
+			api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				assert.Equal(t, "payload", string(b))
+
+				tokens = append(tokens, r.Header.Get("Authorization"))
+				if r.Header.Get("Authorization") != "Bearer "+tt.accepted {
+					w.WriteHeader(http.StatusUnauthorized)
+				}
+			}))
+			defer api.Close()
+
+			c := New(Opts().BaseUrl(api.URL).WithAuth(OAuth2(s.URL, "id", "secret")))
+
+			res, err := c.Post(context.Background(), "/foo", strings.NewReader("payload"))
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantStatus, res.StatusCode())
+			assert.Equal(t, tt.wantTokens, tokens)
+		})
+	}
+}
+
+func TestDefaultClient_OAuth2StreamedBody(t *testing.T) {
+	s := newTokenServer(t)
+
+	pr, pw := io.Pipe()
+	received := make(chan struct{})
+	go func() {
+		io.WriteString(pw, "first")
+		// the rest is written only once the server got the first part, so a buffered body would never be sent
+		select {
+		case <-received:
+		case <-time.After(5 * time.Second):
+		}
+		io.WriteString(pw, " second")
+		pw.Close()
+	}()
+
+	var calls int
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		b := make([]byte, 5)
+		io.ReadFull(r.Body, b)
+		close(received)
+		io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer api.Close()
+
+	c := New(Opts().BaseUrl(api.URL).WithAuth(OAuth2(s.URL, "id", "secret")))
+
+	start := time.Now()
+	res, err := c.Post(context.Background(), "/foo", pr)
+	assert.Nil(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	// a streamed body can't be sent again, so the 401 is returned as is
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode())
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, []string{"client_credentials"}, s.grants())
+}
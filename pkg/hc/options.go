@@ -1,42 +1,284 @@
 package hc
 
-type options struct {
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Options holds the configuration of a client, use Opts to create it
+type Options struct {
 	baseUrl        string
-	timeout        int
-	defaultHeaders headers
-	defaultQuery   Q
+	timeout        time.Duration
+	defaultHeaders http.Header
+	defaultQuery   url.Values
+	retry          *RetryPolicy
+	middlewares    []Middleware
+	errorOnStatus  bool
+	maxBodyBytes   int64
+	codecs         map[string]Codec
+	compressor     Compressor
+	compressAbove  int64
+	decompressors  map[string]Decompressor
+	acceptEncoding bool
+	noDecompress   bool
+	logging        *LogPolicy
+	metrics        Metrics
+	tracer         Tracer
+	propagator     Propagator
+	timings        bool
+	auth           Authenticator
+	transport      transportOptions
+	httpClient     *http.Client
 }
 
 // Opts sets global configuration options
-func Opts() *options {
-	return &options{
+func Opts() *Options {
+	return &Options{
 		baseUrl:        "",
-		timeout:        10,
-		defaultHeaders: headers{},
-		defaultQuery:   Q{},
+		timeout:        10 * time.Second,
+		defaultHeaders: http.Header{},
+		defaultQuery:   url.Values{},
 	}
 }
 
 // BaseUrl sets the base url that all the requests from this client will have
-func (o *options) BaseUrl(v string) *options {
+func (o *Options) BaseUrl(v string) *Options {
 	o.baseUrl = v
 	return o
 }
 
-// Timeout of the requests
-func (o *options) Timeout(v int) *options {
+// Timeout of the requests in seconds
+//
+// Deprecated: use TotalTimeout
+func (o *Options) Timeout(v int) *Options {
+	return o.TotalTimeout(time.Duration(v) * time.Second)
+}
+
+// TotalTimeout limits the whole duration of a request, including reading the response body, 0 means no limit
+func (o *Options) TotalTimeout(v time.Duration) *Options {
 	o.timeout = v
 	return o
 }
 
 // WithDefaultHeader allows to define a bunch of headers that will be included in every request
-func (o *options) WithDefaultHeader(k, v string) *options {
-	o.defaultHeaders[k] = v
+func (o *Options) WithDefaultHeader(k, v string) *Options {
+	o.defaultHeaders.Set(k, v)
+	return o
+}
+
+// WithDefaultHeaders sets default headers with multiple values per key
+func (o *Options) WithDefaultHeaders(v http.Header) *Options {
+	mergeHeader(o.defaultHeaders, v)
+	return o
+}
+
+// AddDefaultHeader adds a value to a default header, keeping the values already added
+func (o *Options) AddDefaultHeader(k, v string) *Options {
+	o.defaultHeaders.Add(k, v)
 	return o
 }
 
 // WithDefaultQuery allows to define a bunch of query string parameters that will be included in every request
-func (o *options) WithDefaultQuery(v Q) *options {
-	o.defaultQuery = v
+func (o *Options) WithDefaultQuery(v Q) *Options {
+	o.defaultQuery = v.values()
+	return o
+}
+
+// WithDefaultQueryValues sets the default query string parameters with multiple values per key
+func (o *Options) WithDefaultQueryValues(v url.Values) *Options {
+	o.defaultQuery = url.Values{}
+	mergeValues(o.defaultQuery, v)
+	return o
+}
+
+// AddDefaultQuery adds a value to a default query string parameter, keeping the values already added
+func (o *Options) AddDefaultQuery(k, v string) *Options {
+	o.defaultQuery.Add(k, v)
+	return o
+}
+
+// WithRetry sets the retry policy applied to every request, it can be overridden per request
+func (o *Options) WithRetry(v *RetryPolicy) *Options {
+	o.retry = v
+	return o
+}
+
+// Use appends middlewares to the chain applied to every request, the first one is the outermost
+func (o *Options) Use(mw ...Middleware) *Options {
+	o.middlewares = append(o.middlewares, mw...)
+	return o
+}
+
+// WithLogging logs every attempt of the requests as a structured event
+func (o *Options) WithLogging(v *LogPolicy) *Options {
+	o.logging = v
+	return o
+}
+
+// WithMetrics records the outcome and the latency of every attempt of the requests
+func (o *Options) WithMetrics(v Metrics) *Options {
+	o.metrics = v
+	return o
+}
+
+// WithTracer starts a span for every request and a child one for every attempt
+func (o *Options) WithTracer(v Tracer) *Options {
+	o.tracer = v
+	return o
+}
+
+// WithPropagator injects the trace context of the requests into their headers, eg. traceparent
+func (o *Options) WithPropagator(v Propagator) *Options {
+	o.propagator = v
+	return o
+}
+
+// CollectTimings records the timing breakdown of every request, see Response.Timings
+func (o *Options) CollectTimings() *Options {
+	o.timings = true
+	return o
+}
+
+// WithAuth authenticates every attempt of the requests, eg. with BasicAuth or BearerAuth
+func (o *Options) WithAuth(v Authenticator) *Options {
+	o.auth = v
+	return o
+}
+
+// ErrorOnStatus makes the requests return an *HTTPError for non 2xx responses, together with the response itself
+func (o *Options) ErrorOnStatus(v bool) *Options {
+	o.errorOnStatus = v
+	return o
+}
+
+// MaxResponseBytes limits the size of the response bodies, reading a larger body fails with a BodyTooLargeError
+func (o *Options) MaxResponseBytes(v int64) *Options {
+	o.maxBodyBytes = v
+	return o
+}
+
+// WithCodec registers a codec for its content type, replacing the built-in json and xml ones if needed
+func (o *Options) WithCodec(v Codec) *Options {
+	if o.codecs == nil {
+		o.codecs = map[string]Codec{}
+	}
+	o.codecs[mediaType(v.ContentType())] = v
+	return o
+}
+
+// Compress compresses the request bodies of at least threshold bytes, eg. with GzipCompressor
+func (o *Options) Compress(v Compressor, threshold int64) *Options {
+	o.compressor = v
+	o.compressAbove = threshold
+	return o
+}
+
+// WithDecompressor registers a decompressor for its encoding, replacing the built-in gzip and deflate ones if needed
+func (o *Options) WithDecompressor(v Decompressor) *Options {
+	if o.decompressors == nil {
+		o.decompressors = map[string]Decompressor{}
+	}
+	o.decompressors[strings.ToLower(v.Encoding())] = v
+	return o
+}
+
+// AcceptEncoding advertises the encodings that can be decoded with the Accept-Encoding header, unless the request
+// sets its own
+func (o *Options) AcceptEncoding() *Options {
+	o.acceptEncoding = true
+	return o
+}
+
+// DisableDecompression returns the response bodies as they are sent by the server, eg. to proxy them
+func (o *Options) DisableDecompression() *Options {
+	o.noDecompress = true
+	return o
+}
+
+// WithTLSConfig sets the base TLS configuration of the transport, the other TLS options are applied on top of it
+func (o *Options) WithTLSConfig(v *tls.Config) *Options {
+	o.transport.tlsConfig = v
+	return o
+}
+
+// WithClientCertificate adds a certificate presented to the servers, eg. for mTLS
+func (o *Options) WithClientCertificate(v tls.Certificate) *Options {
+	o.transport.certificates = append(o.transport.certificates, v)
+	return o
+}
+
+// WithRootCAs sets the certificate authorities used to verify the servers
+func (o *Options) WithRootCAs(v *x509.CertPool) *Options {
+	o.transport.rootCAs = v
+	return o
+}
+
+// MinTLSVersion sets the minimum TLS version accepted, eg. tls.VersionTLS12
+func (o *Options) MinTLSVersion(v uint16) *Options {
+	o.transport.minTLSVersion = v
+	return o
+}
+
+// WithProxy sends all the requests through the proxy, a nil url disables the proxies set in the environment
+func (o *Options) WithProxy(v *url.URL) *Options {
+	o.transport.proxy = http.ProxyURL(v)
+	return o
+}
+
+// MaxIdleConns sets the maximum number of idle connections across all hosts
+func (o *Options) MaxIdleConns(v int) *Options {
+	o.transport.maxIdleConns = v
+	return o
+}
+
+// MaxIdleConnsPerHost sets the maximum number of idle connections kept for every host
+func (o *Options) MaxIdleConnsPerHost(v int) *Options {
+	o.transport.maxIdleConnsPerHost = v
+	return o
+}
+
+// MaxConnsPerHost limits the total number of connections for every host
+func (o *Options) MaxConnsPerHost(v int) *Options {
+	o.transport.maxConnsPerHost = v
+	return o
+}
+
+// IdleConnTimeout sets how long an idle connection is kept open
+func (o *Options) IdleConnTimeout(v time.Duration) *Options {
+	o.transport.idleConnTimeout = v
+	return o
+}
+
+// DialTimeout sets the maximum amount of time to wait for a connection to be established
+func (o *Options) DialTimeout(v time.Duration) *Options {
+	o.transport.dialTimeout = v
+	return o
+}
+
+// TLSHandshakeTimeout sets the maximum amount of time to wait for the TLS handshake
+func (o *Options) TLSHandshakeTimeout(v time.Duration) *Options {
+	o.transport.tlsHandshakeTimeout = v
+	return o
+}
+
+// ResponseHeaderTimeout sets the maximum amount of time to wait for the response headers once the request is sent
+func (o *Options) ResponseHeaderTimeout(v time.Duration) *Options {
+	o.transport.responseHeaderTimeout = v
+	return o
+}
+
+// WithTransport sets the round tripper used by the client, the other transport options are ignored
+func (o *Options) WithTransport(v http.RoundTripper) *Options {
+	o.transport.roundTripper = v
+	return o
+}
+
+// WithHttpClient sets the http client used to send the requests, the timeout and transport options are ignored
+func (o *Options) WithHttpClient(v *http.Client) *Options {
+	o.httpClient = v
 	return o
 }
@@ -2,23 +2,26 @@ package hc
 
 import (
 	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/url"
 	"testing"
+	"time"
 )
 
 func TestOpts(t *testing.T) {
 	var tests = []struct {
 		name  string
-		input *options
-		want  *options
+		input *Options
+		want  *Options
 	}{
 		{
 			"defaults",
 			Opts(),
-			&options{
+			&Options{
 				baseUrl:        "",
-				timeout:        10,
-				defaultHeaders: headers{},
-				defaultQuery:   Q{},
+				timeout:        10 * time.Second,
+				defaultHeaders: http.Header{},
+				defaultQuery:   url.Values{},
 			},
 		},
 		{
@@ -31,18 +34,56 @@ func TestOpts(t *testing.T) {
 				WithDefaultQuery(Q{
 					"task": "test",
 					"page": "1",
-				}),
-			&options{
+				}).
+				WithRetry(Retry().MaxAttempts(5)).
+				ErrorOnStatus(true).
+				MaxResponseBytes(1024).
+				WithCodec(XmlCodec{}).
+				Compress(GzipCompressor{}, 1024).
+				WithDecompressor(GzipDecompressor{}).
+				AcceptEncoding(),
+			&Options{
 				baseUrl: "https://example.com/api/v1",
-				timeout: 20,
-				defaultHeaders: headers{
-					"x-foo": "foo",
-					"x-bar": "bar",
+				timeout: 20 * time.Second,
+				defaultHeaders: http.Header{
+					"X-Foo": {"foo"},
+					"X-Bar": {"bar"},
 				},
-				defaultQuery: Q{
-					"task": "test",
-					"page": "1",
+				defaultQuery: url.Values{
+					"task": {"test"},
+					"page": {"1"},
+				},
+				retry: &RetryPolicy{
+					maxAttempts: 5,
+				},
+				errorOnStatus:  true,
+				maxBodyBytes:   1024,
+				codecs:         map[string]Codec{"application/xml": XmlCodec{}},
+				compressor:     GzipCompressor{},
+				compressAbove:  1024,
+				decompressors:  map[string]Decompressor{"gzip": GzipDecompressor{}},
+				acceptEncoding: true,
+			},
+		},
+		{
+			"with multiple values",
+			Opts().
+				WithDefaultHeaders(http.Header{"accept": {"application/json"}}).
+				AddDefaultHeader("Accept", "text/plain").
+				WithDefaultQueryValues(url.Values{"id": {"1", "2"}}).
+				AddDefaultQuery("id", "3").
+				DisableDecompression().
+				CollectTimings(),
+			&Options{
+				timeout: 10 * time.Second,
+				defaultHeaders: http.Header{
+					"Accept": {"application/json", "text/plain"},
+				},
+				defaultQuery: url.Values{
+					"id": {"1", "2", "3"},
 				},
+				noDecompress: true,
+				timings:      true,
 			},
 		},
 	}
@@ -0,0 +1,93 @@
+package hc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ErrUnresolvedPathParam is returned when a placeholder of the endpoint has no value
+var ErrUnresolvedPathParam = errors.New("hc: unresolved path parameter")
+
+type endpointTemplateKey struct{}
+
+// EndpointTemplate returns the endpoint template of the request, eg. /users/{id}, useful as a low cardinality label
+// for metrics. When the endpoint had no placeholders and no template was set with Request.Template the path of the url
+// is returned.
+func EndpointTemplate(req *http.Request) string {
+	if v, ok := endpointTemplate(req); ok {
+		return v
+	}
+
+	return req.URL.Path
+}
+
+// endpointTemplate returns the template of the request, if it has one
+func endpointTemplate(req *http.Request) (string, bool) {
+	v, ok := req.Context().Value(endpointTemplateKey{}).(string)
+	return v, ok
+}
+
+// placeholder matches the {name} and {+name} placeholders, the names are made of letters, digits, underscores and dots
+var placeholder = regexp.MustCompile(`\{\+?[A-Za-z0-9_.]+\}`)
+
+// splitPath separates the path of the endpoint from its query and fragment, which are never expanded
+func splitPath(endpoint string) (string, string) {
+	if i := strings.IndexAny(endpoint, "?#"); i >= 0 {
+		return endpoint[:i], endpoint[i:]
+	}
+
+	return endpoint, ""
+}
+
+// isTemplate tells if the path of the endpoint has placeholders
+func isTemplate(endpoint string) bool {
+	path, _ := splitPath(endpoint)
+	return placeholder.MatchString(path)
+}
+
+// expandPath replaces the placeholders of the path with the params, any other brace is kept as is. A {name}
+// placeholder is escaped as a single path segment, so that slashes and spaces are encoded, while a {+name} placeholder
+// keeps the slashes, as in RFC 6570.
+func expandPath(template string, params map[string]string) (string, error) {
+	path, rest := splitPath(template)
+
+	var err error
+	res := placeholder.ReplaceAllStringFunc(path, func(m string) string {
+		name := m[1 : len(m)-1]
+		reserved := strings.HasPrefix(name, "+")
+		name = strings.TrimPrefix(name, "+")
+
+		v, ok := params[name]
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("%w: %s", ErrUnresolvedPathParam, name)
+			}
+			return ""
+		}
+
+		if reserved {
+			segments := strings.Split(v, "/")
+			for i, s := range segments {
+				segments[i] = url.PathEscape(s)
+			}
+			return strings.Join(segments, "/")
+		}
+
+		return url.PathEscape(v)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return res + rest, nil
+}
+
+// withEndpointTemplate stores the template in the context of the request
+func withEndpointTemplate(ctx context.Context, template string) context.Context {
+	return context.WithValue(ctx, endpointTemplateKey{}, template)
+}
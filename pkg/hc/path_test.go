@@ -0,0 +1,123 @@
+package hc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jacoz/go-http-client/pkg/hc/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExpandPath(t *testing.T) {
+	var tests = []struct {
+		name      string
+		template  string
+		params    map[string]string
+		want      string
+		wantError error
+	}{
+		{
+			"without placeholders",
+			"/users",
+			nil,
+			"/users",
+			nil,
+		},
+		{
+			"with placeholders",
+			"/users/{id}/orders/{orderId}",
+			map[string]string{"id": "42", "orderId": "a1"},
+			"/users/42/orders/a1",
+			nil,
+		},
+		{
+			"escapes the values",
+			"/files/{name}",
+			map[string]string{"name": "a/b c?d"},
+			"/files/a%2Fb%20c%3Fd",
+			nil,
+		},
+		{
+			"reserved expansion keeps the slashes",
+			"/files/{+path}",
+			map[string]string{"path": "dir/sub dir/file.txt"},
+			"/files/dir/sub%20dir/file.txt",
+			nil,
+		},
+		{
+			"query and fragment are kept",
+			`/search/{kind}?filter={"a":1}&id={id}#{top}`,
+			map[string]string{"kind": "users"},
+			`/search/users?filter={"a":1}&id={id}#{top}`,
+			nil,
+		},
+		{
+			"other braces are kept",
+			`/users/{id}/{"a":1}/{id`,
+			map[string]string{"id": "42"},
+			`/users/42/{"a":1}/{id`,
+			nil,
+		},
+		{
+			"unresolved placeholder",
+			"/users/{id}/orders/{orderId}",
+			map[string]string{"id": "42"},
+			"",
+			ErrUnresolvedPathParam,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandPath(tt.template, tt.params)
+
+			assert.Equal(t, tt.want, got)
+			assert.True(t, errors.Is(err, tt.wantError))
+		})
+	}
+}
+
+func TestDefaultClient_PathParams(t *testing.T) {
+	ctx := context.Background()
+
+	goHttpClientMock := mocks.NewGoHttpClient(t)
+	goHttpClientMock.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.String() == "https://example.com/api/users/a%2Fb/orders/1" &&
+			EndpointTemplate(req) == "/users/{id}/orders/{orderId}"
+	})).Return(&http.Response{}, nil)
+	goHttpClientMock.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.String() == "https://example.com/api/users" &&
+			req.Context() == ctx &&
+			EndpointTemplate(req) == "/api/users"
+	})).Return(&http.Response{}, nil)
+
+	c := newClient(Opts().BaseUrl("https://example.com/api"))
+	c.client = goHttpClientMock
+
+	_, err := c.Get(ctx, "/users/{id}/orders/{orderId}", nil, Req().Path("id", "a/b").Path("orderId", "1"))
+	assert.Nil(t, err)
+
+	_, err = c.Get(ctx, "/users", nil)
+	assert.Nil(t, err)
+
+	_, err = c.Get(ctx, "/users/{id}", nil)
+	assert.True(t, errors.Is(err, ErrUnresolvedPathParam))
+}
+
+func TestDefaultClient_BracesInQuery(t *testing.T) {
+	ctx := context.Background()
+
+	goHttpClientMock := mocks.NewGoHttpClient(t)
+	goHttpClientMock.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.Query().Get("filter") == `{"a":1}` && req.Context() == ctx
+	})).Return(&http.Response{}, nil)
+
+	c := newClient(Opts().BaseUrl("https://example.com"))
+	c.client = goHttpClientMock
+
+	_, err := c.Get(ctx, `/search?filter={"a":1}`, nil)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,81 @@
+package hc
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+)
+
+// ProblemContentType is the media type of the RFC 9457 problem details
+const ProblemContentType = "application/problem+json"
+
+// Problem is a problem details object as defined by RFC 7807 and RFC 9457
+type Problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// Extensions holds all the members that are not defined by the RFC
+	Extensions map[string]any `json:"-"`
+}
+
+// UnmarshalJSON decodes the problem, members with an invalid type are ignored as required by the RFC
+func (p *Problem) UnmarshalJSON(b []byte) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(b, &members); err != nil {
+		return err
+	}
+
+	fields := map[string]any{
+		"type":     &p.Type,
+		"title":    &p.Title,
+		"status":   &p.Status,
+		"detail":   &p.Detail,
+		"instance": &p.Instance,
+	}
+
+	for k, v := range members {
+		if f, ok := fields[k]; ok {
+			json.Unmarshal(v, f)
+			continue
+		}
+
+		var ext any
+		if err := json.Unmarshal(v, &ext); err != nil {
+			return err
+		}
+		if p.Extensions == nil {
+			p.Extensions = map[string]any{}
+		}
+		p.Extensions[k] = ext
+	}
+
+	// when the type is not present it must be assumed to be about:blank
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+
+	return nil
+}
+
+// isProblem tells if the headers declare a problem details body
+func isProblem(h http.Header) bool {
+	t, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	return err == nil && t == ProblemContentType
+}
+
+// parseProblem decodes a problem details body, it returns nil if the body is not a problem
+func parseProblem(h http.Header, body []byte) (*Problem, error) {
+	if !isProblem(h) {
+		return nil, nil
+	}
+
+	var p Problem
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
@@ -0,0 +1,111 @@
+package hc
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblem_UnmarshalJSON(t *testing.T) {
+	var tests = []struct {
+		name      string
+		input     string
+		want      Problem
+		wantError bool
+	}{
+		{
+			"empty",
+			`{}`,
+			Problem{
+				Type: "about:blank",
+			},
+			false,
+		},
+		{
+			"standard members",
+			`{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.","status":403,"detail":"Your current balance is 30, but that costs 50.","instance":"/account/12345/msgs/abc"}`,
+			Problem{
+				Type:     "https://example.com/probs/out-of-credit",
+				Title:    "You do not have enough credit.",
+				Status:   403,
+				Detail:   "Your current balance is 30, but that costs 50.",
+				Instance: "/account/12345/msgs/abc",
+			},
+			false,
+		},
+		{
+			"extension members",
+			`{"type":"https://example.com/probs/out-of-credit","balance":30,"accounts":["/account/12345"]}`,
+			Problem{
+				Type: "https://example.com/probs/out-of-credit",
+				Extensions: map[string]any{
+					"balance":  float64(30),
+					"accounts": []any{"/account/12345"},
+				},
+			},
+			false,
+		},
+		{
+			"invalid member type",
+			`{"title":"Bad","status":"400"}`,
+			Problem{
+				Type:  "about:blank",
+				Title: "Bad",
+			},
+			false,
+		},
+		{
+			"invalid json",
+			`[]`,
+			Problem{},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Problem
+			err := json.Unmarshal([]byte(tt.input), &got)
+
+			assert.Equal(t, tt.wantError, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseProblem(t *testing.T) {
+	var tests = []struct {
+		name   string
+		header http.Header
+		body   string
+		want   *Problem
+	}{
+		{
+			"not a problem",
+			http.Header{"Content-Type": {"application/json"}},
+			`{"title":"Bad"}`,
+			nil,
+		},
+		{
+			"problem",
+			http.Header{"Content-Type": {"application/problem+json; charset=utf-8"}},
+			`{"title":"Bad","status":400}`,
+			&Problem{
+				Type:   "about:blank",
+				Title:  "Bad",
+				Status: 400,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseProblem(tt.header, []byte(tt.body))
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package hc
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryEncoder is implemented by the types that encode themselves into query string values
+type QueryEncoder interface {
+	EncodeValues(key string, v *url.Values) error
+}
+
+var (
+	timeType         = reflect.TypeOf(time.Time{})
+	queryEncoderType = reflect.TypeOf((*QueryEncoder)(nil)).Elem()
+)
+
+// EncodeQuery encodes a struct into query string values using the `url` tags of its fields.
+//
+// The tag is made of the name of the parameter followed by comma separated options:
+//   - omitempty: the zero values are skipped
+//   - comma, space: slices are joined in a single value instead of repeating the parameter
+//   - int: booleans are encoded as 1 and 0
+//   - unix, unixmilli: times are encoded as timestamps, otherwise RFC 3339 or the format in the `layout` tag is used
+//
+// A "-" name skips the field, nil pointers are always skipped, embedded structs are flattened and nested structs are
+// encoded as parent[child]. Fields implementing QueryEncoder encode themselves.
+func EncodeQuery(v any) (url.Values, error) {
+	res := url.Values{}
+
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return res, nil
+		}
+		val = val.Elem()
+	}
+
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("hc: query must be a struct, got %T", v)
+	}
+
+	return res, encodeStruct(res, "", val)
+}
+
+// GetQuery performs a GET request with the query parameters encoded from the struct v, see EncodeQuery. The
+// parameters replace the ones of the request with the same names, as with Request.QueryStruct.
+func GetQuery(ctx context.Context, c Client, endpoint string, v any, r ...*Request) (*Response, error) {
+	values, err := EncodeQuery(v)
+	if err != nil {
+		return nil, err
+	}
+
+	req := Req()
+	if len(r) > 0 && r[0] != nil {
+		req = r[0].clone()
+	}
+	if req.query == nil {
+		req.query = url.Values{}
+	}
+	mergeValues(req.query, values)
+
+	return c.Get(ctx, endpoint, nil, req)
+}
+
+func encodeStruct(res url.Values, prefix string, val reflect.Value) error {
+	t := val.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		tag := field.Tag.Get("url")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts := parseTag(tag)
+		fv := val.Field(i)
+
+		if field.Anonymous && name == "" {
+			for fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct && fv.Type() != timeType {
+				if err := encodeStruct(res, prefix, fv); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		if prefix != "" {
+			name = fmt.Sprintf("%s[%s]", prefix, name)
+		}
+
+		if err := encodeField(res, name, fv, opts, field.Tag.Get("layout")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func encodeField(res url.Values, name string, v reflect.Value, opts tagOptions, layout string) error {
+	if opts.has("omitempty") && isEmpty(v) {
+		return nil
+	}
+
+	for {
+		if e, ok := asEncoder(v); ok {
+			return e.EncodeValues(name, &res)
+		}
+
+		if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface {
+			break
+		}
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Type() == timeType:
+		res.Add(name, formatTime(v.Interface().(time.Time), opts, layout))
+		return nil
+	case v.Kind() == reflect.Struct:
+		return encodeStruct(res, name, v)
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			res.Add(name, string(v.Bytes()))
+			return nil
+		}
+
+		values := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s, err := formatValue(v.Index(i), opts, layout)
+			if err != nil {
+				return err
+			}
+			values = append(values, s)
+		}
+
+		switch {
+		case opts.has("comma"):
+			res.Add(name, strings.Join(values, ","))
+		case opts.has("space"):
+			res.Add(name, strings.Join(values, " "))
+		default:
+			for _, s := range values {
+				res.Add(name, s)
+			}
+		}
+		return nil
+	}
+
+	s, err := formatValue(v, opts, layout)
+	if err != nil {
+		return err
+	}
+	res.Add(name, s)
+
+	return nil
+}
+
+// asEncoder returns the value as a QueryEncoder, if it or its address implements the interface
+func asEncoder(v reflect.Value) (QueryEncoder, bool) {
+	if v.Kind() != reflect.Interface && v.Type().Implements(queryEncoderType) {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return nil, false
+		}
+		return v.Interface().(QueryEncoder), true
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(queryEncoderType) {
+		return v.Addr().Interface().(QueryEncoder), true
+	}
+
+	return nil, false
+}
+
+// formatValue converts a scalar value to its query string representation
+func formatValue(v reflect.Value, opts tagOptions, layout string) (string, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Type() == timeType {
+		return formatTime(v.Interface().(time.Time), opts, layout), nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		if opts.has("int") {
+			if v.Bool() {
+				return "1", nil
+			}
+			return "0", nil
+		}
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	}
+
+	return "", fmt.Errorf("hc: unsupported query value of type %s", v.Type())
+}
+
+func formatTime(t time.Time, opts tagOptions, layout string) string {
+	switch {
+	case opts.has("unix"):
+		return strconv.FormatInt(t.Unix(), 10)
+	case opts.has("unixmilli"):
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case layout != "":
+		return t.Format(layout)
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String:
+		return v.Len() == 0
+	}
+
+	return v.IsZero()
+}
+
+type tagOptions []string
+
+func parseTag(tag string) (string, tagOptions) {
+	parts := strings.Split(tag, ",")
+	return parts[0], parts[1:]
+}
+
+func (o tagOptions) has(v string) bool {
+	for _, opt := range o {
+		if opt == v {
+			return true
+		}
+	}
+
+	return false
+}
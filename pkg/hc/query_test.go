@@ -0,0 +1,194 @@
+package hc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jacoz/go-http-client/pkg/hc/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type sortOrder struct {
+	field string
+	desc  bool
+}
+
+func (s sortOrder) EncodeValues(key string, v *url.Values) error {
+	if s.field == "" {
+		return errors.New("empty sort field")
+	}
+
+	dir := "asc"
+	if s.desc {
+		dir = "desc"
+	}
+	v.Set(key, s.field+":"+dir)
+
+	return nil
+}
+
+type Pagination struct {
+	Page    int `url:"page,omitempty"`
+	PerPage int `url:"per_page,omitempty"`
+}
+
+type filters struct {
+	Pagination
+	Query    string    `url:"q"`
+	IDs      []int     `url:"id"`
+	Tags     []string  `url:"tags,comma,omitempty"`
+	Active   *bool     `url:"active"`
+	Verified bool      `url:"verified,int"`
+	From     time.Time `url:"from" layout:"2006-01-02"`
+	To       time.Time `url:"to,unix,omitempty"`
+	Price    float64   `url:"price,omitempty"`
+	Sort     sortOrder `url:"sort"`
+	Owner    struct {
+		Name string `url:"name"`
+	} `url:"owner"`
+	Ignored  string `url:"-"`
+	Untagged string
+	internal string
+}
+
+func TestEncodeQuery(t *testing.T) {
+	active := true
+	from := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	var tests = []struct {
+		name      string
+		input     any
+		want      url.Values
+		wantError string
+	}{
+		{
+			"nil pointer",
+			(*filters)(nil),
+			url.Values{},
+			"",
+		},
+		{
+			"not a struct",
+			42,
+			nil,
+			"hc: query must be a struct, got int",
+		},
+		{
+			"all the fields",
+			&filters{
+				Pagination: Pagination{Page: 2},
+				Query:      "foo bar",
+				IDs:        []int{1, 2, 3},
+				Tags:       []string{"a", "b"},
+				Active:     &active,
+				Verified:   true,
+				From:       from,
+				To:         from.Add(24 * time.Hour),
+				Price:      9.99,
+				Sort:       sortOrder{field: "name", desc: true},
+				Ignored:    "ignored",
+				Untagged:   "untagged",
+				internal:   "internal",
+			},
+			url.Values{
+				"page":        {"2"},
+				"q":           {"foo bar"},
+				"id":          {"1", "2", "3"},
+				"tags":        {"a,b"},
+				"active":      {"true"},
+				"verified":    {"1"},
+				"from":        {"2022-10-01"},
+				"to":          {"1664668800"},
+				"price":       {"9.99"},
+				"sort":        {"name:desc"},
+				"owner[name]": {""},
+				"Untagged":    {"untagged"},
+			},
+			"",
+		},
+		{
+			"omit empty values",
+			filters{Sort: sortOrder{field: "id"}},
+			url.Values{
+				"q":           {""},
+				"verified":    {"0"},
+				"from":        {"0001-01-01"},
+				"sort":        {"id:asc"},
+				"owner[name]": {""},
+				"Untagged":    {""},
+			},
+			"",
+		},
+		{
+			"encoder error",
+			filters{},
+			nil,
+			"empty sort field",
+		},
+		{
+			"unsupported type",
+			struct {
+				M map[string]string `url:"m"`
+			}{M: map[string]string{"a": "b"}},
+			nil,
+			"hc: unsupported query value of type map[string]string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeQuery(tt.input)
+
+			if tt.wantError != "" {
+				assert.EqualError(t, err, tt.wantError)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDefaultClient_QueryStruct(t *testing.T) {
+	ctx := context.Background()
+	c := New(Opts().BaseUrl("https://example.com"))
+
+	_, err := c.Get(ctx, "/foo", nil, Req().QueryStruct(42))
+	assert.EqualError(t, err, "hc: query must be a struct, got int")
+
+	r := Req().AddQuery("q", "old").AddQuery("keep", "1").QueryStruct(struct {
+		Query string `url:"q"`
+	}{"new"})
+	assert.Equal(t, url.Values{"q": {"new"}, "keep": {"1"}}, r.query)
+}
+
+func TestGetQuery(t *testing.T) {
+	ctx := context.Background()
+
+	goHttpClientMock := mocks.NewGoHttpClient(t)
+	goHttpClientMock.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.String() == "https://example.com/search?keep=1&q=new&tags=a&tags=b"
+	})).Return(&http.Response{StatusCode: 200}, nil)
+
+	c := newClient(Opts().BaseUrl("https://example.com"))
+	c.client = goHttpClientMock
+
+	r := Req().AddQuery("q", "old").AddQuery("keep", "1")
+	res, err := GetQuery(ctx, c, "/search", struct {
+		Query string   `url:"q"`
+		Tags  []string `url:"tags"`
+	}{"new", []string{"a", "b"}}, r)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 200, res.StatusCode())
+	assert.Equal(t, url.Values{"q": {"old"}, "keep": {"1"}}, r.query)
+
+	_, err = GetQuery(ctx, c, "/search", 42)
+	assert.EqualError(t, err, "hc: query must be a struct, got int")
+}
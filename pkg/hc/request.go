@@ -1,45 +1,284 @@
 package hc
 
-import "fmt"
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
 
-type headers map[string]string
-
-type request struct {
-	headers headers
-	query   Q
+// Request holds the configuration of a single request, use Req to create it
+type Request struct {
+	method        string
+	endpoint      string
+	body          any
+	headers       http.Header
+	query         url.Values
+	removeHeaders []string
+	removeQuery   []string
+	retry         *RetryPolicy
+	middlewares   []Middleware
+	errorOnStatus *bool
+	timeout       time.Duration
+	pathParams    map[string]string
+	template      string
+	stream        bool
+	maxBodyBytes  int64
+	compressor    Compressor
+	noCompression bool
+	noDecompress  bool
+	timings       bool
+	auth          Authenticator
+	err           error
 }
 
 // Req allows to define extra configuration for a request
-func Req() *request {
-	return &request{
-		headers: headers{},
+func Req() *Request {
+	return &Request{
+		headers: http.Header{},
 		query:   nil,
 	}
 }
 
+// clone returns a copy of the request that can be changed without affecting the original one
+func (r *Request) clone() *Request {
+	c := *r
+	c.middlewares = r.middlewares[:len(r.middlewares):len(r.middlewares)]
+	c.removeHeaders = r.removeHeaders[:len(r.removeHeaders):len(r.removeHeaders)]
+	c.removeQuery = r.removeQuery[:len(r.removeQuery):len(r.removeQuery)]
+	c.headers = r.headers.Clone()
+	if r.pathParams != nil {
+		c.pathParams = map[string]string{}
+		for k, v := range r.pathParams {
+			c.pathParams[k] = v
+		}
+	}
+	if r.query != nil {
+		c.query = url.Values{}
+		mergeValues(c.query, r.query)
+	}
+
+	return &c
+}
+
+// Method sets the method of the request, used by Send
+func (r *Request) Method(v string) *Request {
+	r.method = v
+	return r
+}
+
+// Endpoint sets the endpoint of the request, used by Send
+func (r *Request) Endpoint(v string) *Request {
+	r.endpoint = v
+	return r
+}
+
+// Body sets the body of the request, it allows to send a body with any method, eg. GET or DELETE. Values that aren't
+// an io.Reader are encoded with the codec of the Content-Type header, json by default. A body passed directly to the
+// method of the client takes precedence.
+func (r *Request) Body(v any) *Request {
+	r.body = v
+	return r
+}
+
 // Query sets a query string for the request
-func (r *request) Query(v Q) *request {
-	r.query = v
+func (r *Request) Query(v Q) *Request {
+	r.query = v.values()
+	return r
+}
+
+// QueryValues sets a query string with multiple values per key for the request
+func (r *Request) QueryValues(v url.Values) *Request {
+	r.query = url.Values{}
+	mergeValues(r.query, v)
+	return r
+}
+
+// AddQuery adds a value to a query string parameter of the request, keeping the values already added
+func (r *Request) AddQuery(k, v string) *Request {
+	if r.query == nil {
+		r.query = url.Values{}
+	}
+	r.query.Add(k, v)
+	return r
+}
+
+// QueryStruct adds to the query string of the request the fields of a struct, encoded with EncodeQuery. Encoding
+// errors are returned when the request is performed.
+func (r *Request) QueryStruct(v any) *Request {
+	values, err := EncodeQuery(v)
+	if err != nil {
+		r.err = err
+		return r
+	}
+
+	if r.query == nil {
+		r.query = url.Values{}
+	}
+	mergeValues(r.query, values)
+	return r
+}
+
+// WithoutQuery removes a default query string parameter of the client for the request
+func (r *Request) WithoutQuery(k string) *Request {
+	r.removeQuery = append(r.removeQuery, k)
+	return r
+}
+
+// Path sets the value of a placeholder of the endpoint, eg. Path("id", "42") for /users/{id}
+func (r *Request) Path(k, v string) *Request {
+	if r.pathParams == nil {
+		r.pathParams = map[string]string{}
+	}
+	r.pathParams[k] = v
+	return r
+}
+
+// Template sets the endpoint template reported to the metrics, logs and traces, for the endpoints built without
+// placeholders, eg. Template("/users/{id}") for "/users/"+id
+func (r *Request) Template(v string) *Request {
+	r.template = v
 	return r
 }
 
 // WithHeader sets an extra header for the request
-func (r *request) WithHeader(k, v string) *request {
-	r.headers[k] = v
+func (r *Request) WithHeader(k, v string) *Request {
+	r.headers.Set(k, v)
+	return r
+}
+
+// WithHeaders sets extra headers with multiple values per key for the request
+func (r *Request) WithHeaders(v http.Header) *Request {
+	mergeHeader(r.headers, v)
+	return r
+}
+
+// AddHeader adds a value to a header of the request, keeping the values already added
+func (r *Request) AddHeader(k, v string) *Request {
+	r.headers.Add(k, v)
+	return r
+}
+
+// WithoutHeader removes a default header of the client for the request
+func (r *Request) WithoutHeader(k string) *Request {
+	r.removeHeaders = append(r.removeHeaders, k)
 	return r
 }
 
 // WithContentType is a shortcut for setting the Content-Type header
-func (r *request) WithContentType(v string) *request {
+func (r *Request) WithContentType(v string) *Request {
 	return r.WithHeader("Content-Type", v)
 }
 
 // WithJsonContentType is a shortcut for setting the Content-Type header for json requests
-func (r *request) WithJsonContentType() *request {
-	return r.WithContentType("application/json")
+func (r *Request) WithJsonContentType() *Request {
+	return r.WithContentType(jsonContentType)
 }
 
 // WithBearerToken is a shortcut for setting the Authorization header, it will prepend to the token the "Bearer" keyword
-func (r *request) WithBearerToken(v string) *request {
+func (r *Request) WithBearerToken(v string) *Request {
 	return r.WithHeader("Authorization", fmt.Sprintf("Bearer %s", v))
 }
+
+// WithAuth overrides the authenticator of the client
+func (r *Request) WithAuth(v Authenticator) *Request {
+	r.auth = v
+	return r
+}
+
+// WithRetry overrides the retry policy of the client for the request
+func (r *Request) WithRetry(v *RetryPolicy) *Request {
+	r.retry = v
+	return r
+}
+
+// Use appends middlewares for the request, they run inside the ones of the client
+func (r *Request) Use(mw ...Middleware) *Request {
+	r.middlewares = append(r.middlewares, mw...)
+	return r
+}
+
+// ErrorOnStatus overrides the ErrorOnStatus option of the client for the request
+func (r *Request) ErrorOnStatus(v bool) *Request {
+	r.errorOnStatus = &v
+	return r
+}
+
+// Timeout sets a deadline for the request, including reading the response body, it replaces the total timeout of the
+// client so it can be both shorter and longer
+func (r *Request) Timeout(v time.Duration) *Request {
+	r.timeout = v
+	return r
+}
+
+// Stream disables the buffering of the response body, which can then be read only once
+func (r *Request) Stream() *Request {
+	r.stream = true
+	return r
+}
+
+// MaxResponseBytes overrides the MaxResponseBytes option of the client, a negative value disables the limit
+func (r *Request) MaxResponseBytes(v int64) *Request {
+	r.maxBodyBytes = v
+	return r
+}
+
+// Compress compresses the body of the request regardless of its size, overriding the compressor of the client
+func (r *Request) Compress(v Compressor) *Request {
+	r.compressor = v
+	return r
+}
+
+// WithoutCompression sends the body of the request as it is, even if the client compresses the bodies
+func (r *Request) WithoutCompression() *Request {
+	r.noCompression = true
+	return r
+}
+
+// CollectTimings records the timing breakdown of the request, see Response.Timings
+func (r *Request) CollectTimings() *Request {
+	r.timings = true
+	return r
+}
+
+// WithoutDecompression returns the response body as it is sent by the server, eg. to proxy it
+func (r *Request) WithoutDecompression() *Request {
+	r.noDecompress = true
+	return r
+}
+
+// MethodName returns the method set with Method, used by Send
+func (r *Request) MethodName() string {
+	return r.method
+}
+
+// EndpointName returns the endpoint set with Endpoint, used by Send
+func (r *Request) EndpointName() string {
+	return r.endpoint
+}
+
+// BodyValue returns the body set with Body
+func (r *Request) BodyValue() any {
+	return r.body
+}
+
+// Header returns a copy of the headers of the request
+func (r *Request) Header() http.Header {
+	return r.headers.Clone()
+}
+
+// QueryParams returns a copy of the query parameters of the request
+func (r *Request) QueryParams() url.Values {
+	res := url.Values{}
+	mergeValues(res, r.query)
+	return res
+}
+
+// PathParams returns a copy of the values of the endpoint placeholders
+func (r *Request) PathParams() map[string]string {
+	res := map[string]string{}
+	for k, v := range r.pathParams {
+		res[k] = v
+	}
+	return res
+}
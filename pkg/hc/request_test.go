@@ -2,20 +2,22 @@ package hc
 
 import (
 	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/url"
 	"testing"
 )
 
 func TestReq(t *testing.T) {
 	var tests = []struct {
 		name  string
-		input *request
-		want  *request
+		input *Request
+		want  *Request
 	}{
 		{
 			"defaults",
 			Req(),
-			&request{
-				headers: headers{},
+			&Request{
+				headers: http.Header{},
 				query:   nil,
 			},
 		},
@@ -29,18 +31,58 @@ func TestReq(t *testing.T) {
 				WithHeader("x-foo", "foo").
 				WithHeader("x-bar", "bar").
 				WithJsonContentType().
-				WithBearerToken("foo"),
-			&request{
-				headers: headers{
-					"x-foo":         "foo",
-					"x-bar":         "bar",
-					"Content-Type":  "application/json",
-					"Authorization": "Bearer foo",
+				WithBearerToken("foo").
+				WithRetry(Retry()).
+				ErrorOnStatus(false).
+				Stream().
+				MaxResponseBytes(1024).
+				Compress(GzipCompressor{}).
+				Template("/users/{id}"),
+			&Request{
+				headers: http.Header{
+					"X-Foo":         {"foo"},
+					"X-Bar":         {"bar"},
+					"Content-Type":  {"application/json"},
+					"Authorization": {"Bearer foo"},
 				},
-				query: Q{
-					"task": "test",
-					"page": "1",
+				query: url.Values{
+					"task": {"test"},
+					"page": {"1"},
 				},
+				retry: &RetryPolicy{
+					maxAttempts: 3,
+				},
+				errorOnStatus: new(bool),
+				stream:        true,
+				maxBodyBytes:  1024,
+				compressor:    GzipCompressor{},
+				template:      "/users/{id}",
+			},
+		},
+		{
+			"with multiple values",
+			Req().
+				QueryValues(url.Values{"id": {"1", "2"}}).
+				AddQuery("id", "3").
+				WithoutQuery("page").
+				WithHeaders(http.Header{"accept": {"application/json"}}).
+				AddHeader("Accept", "text/plain").
+				WithoutHeader("X-Default").
+				WithoutCompression().
+				WithoutDecompression().
+				CollectTimings(),
+			&Request{
+				headers: http.Header{
+					"Accept": {"application/json", "text/plain"},
+				},
+				query: url.Values{
+					"id": {"1", "2", "3"},
+				},
+				removeHeaders: []string{"X-Default"},
+				removeQuery:   []string{"page"},
+				noCompression: true,
+				noDecompress:  true,
+				timings:       true,
 			},
 		},
 	}
@@ -51,3 +93,18 @@ func TestReq(t *testing.T) {
 		})
 	}
 }
+
+func TestRequest_Accessors(t *testing.T) {
+	r := Req().Method(http.MethodPost).Endpoint("/foo").Body("bar").WithHeader("X-Foo", "foo").AddQuery("page", "1")
+
+	assert.Equal(t, http.MethodPost, r.MethodName())
+	assert.Equal(t, "/foo", r.EndpointName())
+	assert.Equal(t, "bar", r.BodyValue())
+	assert.Equal(t, map[string]string{}, r.PathParams())
+
+	// the accessors return copies, which don't change the request
+	r.Header().Set("X-Foo", "changed")
+	r.QueryParams().Set("page", "2")
+	assert.Equal(t, "foo", r.Header().Get("X-Foo"))
+	assert.Equal(t, url.Values{"page": {"1"}}, r.QueryParams())
+}
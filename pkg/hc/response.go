@@ -4,67 +4,263 @@ import (
 	"bytes"
 	"encoding/json"
 	"encoding/xml"
+	"io"
 	"net/http"
 )
 
-type response struct {
+// Response wraps the http response with helpers to check the status and decode the body.
+//
+// The body is read and buffered the first time it is needed, so that all the accessors can be called repeatedly and
+// in any order. In streaming mode (see Request.Stream) the body is never buffered and can be read only once.
+type Response struct {
 	response *http.Response
+
+	body  []byte
+	err   error
+	ready bool
+
+	// maxBytes limits the size of the body, 0 or less means no limit
+	maxBytes int64
+	stream   bool
+	codecs   map[string]Codec
+	timings  *timingsRecorder
+}
+
+// NewResponse wraps an http response, useful to build responses in Client implementations and test doubles
+func NewResponse(v *http.Response) *Response {
+	return &Response{response: v}
+}
+
+// Bytes returns the response body, a BodyTooLargeError is returned when it exceeds MaxResponseBytes
+func (r *Response) Bytes() ([]byte, error) {
+	if r.stream {
+		body := r.Body()
+		defer body.Close()
+
+		return io.ReadAll(body)
+	}
+
+	r.prepare()
+	return r.body, r.err
+}
+
+// String returns the response body as a string
+func (r *Response) String() (string, error) {
+	b, err := r.Bytes()
+	return string(b), err
+}
+
+// Body returns a reader of the response body, in streaming mode it's the body of the http response itself
+func (r *Response) Body() io.ReadCloser {
+	r.prepare()
+	if !r.stream {
+		return io.NopCloser(bytes.NewReader(r.body))
+	}
+
+	if r.response.Body == nil {
+		return http.NoBody
+	}
+
+	return r.response.Body
+}
+
+// Decode decodes the response with the codec of its Content-Type, an ErrUnsupportedContentType error is returned when
+// there is none
+func (r *Response) Decode(v any) error {
+	codec, err := lookupCodec(r.codecs, r.response.Header.Get("Content-Type"))
+	if err != nil {
+		return err
+	}
+
+	b, err := r.Bytes()
+	if err != nil {
+		return err
+	}
+
+	return codec.Unmarshal(b, v)
 }
 
 // UnmarshalJson decodes a json response into a struct
-func (r *response) UnmarshalJson(v any) error {
-	body := r.response.Body
-	defer body.Close()
+func (r *Response) UnmarshalJson(v any) error {
+	if r.stream {
+		body := r.Body()
+		defer body.Close()
+
+		return json.NewDecoder(body).Decode(v)
+	}
+
+	b, err := r.Bytes()
+	if err != nil {
+		return err
+	}
 
-	return json.NewDecoder(body).Decode(&v)
+	return JsonCodec{}.Unmarshal(b, v)
 }
 
 // UnmarshalXml decodes a xml response into a struct
-func (r *response) UnmarshalXml(v any) error {
-	body := r.Get().Body
-	defer body.Close()
+func (r *Response) UnmarshalXml(v any) error {
+	if r.stream {
+		body := r.Body()
+		defer body.Close()
+
+		return xml.NewDecoder(body).Decode(v)
+	}
 
-	return xml.Unmarshal(r.Debug(), &v)
+	b, err := r.Bytes()
+	if err != nil {
+		return err
+	}
+
+	return XmlCodec{}.Unmarshal(b, v)
 }
 
 // StatusCode returns the response status code
-func (r *response) StatusCode() int {
+func (r *Response) StatusCode() int {
 	return r.response.StatusCode
 }
 
 // Ok is a shortcut to check if the response has the status 200
-func (r *response) Ok() bool {
+func (r *Response) Ok() bool {
 	return r.response.StatusCode == http.StatusOK
 }
 
 // Created is a shortcut to check if the response has the status 201
-func (r *response) Created() bool {
+func (r *Response) Created() bool {
 	return r.response.StatusCode == http.StatusCreated
 }
 
 // NoContent is a shortcut to check if the response has the status 204
-func (r *response) NoContent() bool {
+func (r *Response) NoContent() bool {
 	return r.response.StatusCode == http.StatusNoContent
 }
 
 // BadRequest is a shortcut to check if the response has the status 400
-func (r *response) BadRequest() bool {
+func (r *Response) BadRequest() bool {
 	return r.response.StatusCode == http.StatusBadRequest
 }
 
 // NotFound is a shortcut to check if the response has the status 404
-func (r *response) NotFound() bool {
+func (r *Response) NotFound() bool {
 	return r.response.StatusCode == http.StatusNotFound
 }
 
-// Debug returns the response object
-func (r *response) Debug() []byte {
-	buf := new(bytes.Buffer)
-	buf.ReadFrom(r.response.Body)
-	return buf.Bytes()
+// Debug returns the response body, ignoring the read errors
+func (r *Response) Debug() []byte {
+	b, _ := r.Bytes()
+	return b
+}
+
+// Problem decodes an application/problem+json response, it returns nil if the response has a different content type.
+// The body can still be read afterwards.
+func (r *Response) Problem() (*Problem, error) {
+	if !isProblem(r.response.Header) {
+		return nil, nil
+	}
+
+	b, err := r.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseProblem(r.response.Header, b)
+}
+
+// prepare reads the whole body once and closes it, the body of the http response is replaced with the buffered one.
+// In streaming mode the body is only wrapped to enforce the size limit.
+func (r *Response) prepare() {
+	if r.ready {
+		return
+	}
+	r.ready = true
+
+	body := r.response.Body
+	if body == nil {
+		return
+	}
+
+	if r.stream {
+		r.response.Body = r.limit(body)
+		return
+	}
+
+	defer body.Close()
+
+	r.body, r.err = io.ReadAll(r.limit(body))
+	r.response.Body = io.NopCloser(bytes.NewReader(r.body))
+}
+
+// buffer reads the whole body and closes it, also in streaming mode unless the body is already being read
+func (r *Response) buffer() ([]byte, error) {
+	if !r.ready {
+		r.stream = false
+	}
+
+	r.prepare()
+	return r.body, r.err
+}
+
+// limit wraps the body so that reading more than maxBytes fails, bodies declaring a larger Content-Length fail
+// before reading anything
+func (r *Response) limit(body io.ReadCloser) io.ReadCloser {
+	if r.maxBytes <= 0 {
+		return body
+	}
+
+	if r.response.ContentLength > r.maxBytes {
+		err := &BodyTooLargeError{Limit: r.maxBytes, ContentLength: r.response.ContentLength}
+		return readCloser{errorReader{err}, body}
+	}
+
+	err := &BodyTooLargeError{Limit: r.maxBytes, ContentLength: -1}
+	return readCloser{&limitReader{r: body, n: r.maxBytes, err: err}, body}
+}
+
+// Timings returns the timing breakdown of the request, it's empty unless the timings are collected
+func (r *Response) Timings() Timings {
+	if r.timings == nil {
+		return Timings{}
+	}
+
+	return r.timings.timings()
 }
 
 // Get returns the response object
-func (r *response) Get() *http.Response {
+func (r *Response) Get() *http.Response {
 	return r.response
 }
+
+// limitReader reads at most n bytes, then fails if the underlying reader has more data
+type limitReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		var b [1]byte
+		n, err := l.r.Read(b[:])
+		if n > 0 {
+			l.r = errorReader{l.err}
+			return 0, l.err
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+
+	return n, err
+}
+
+// errorReader always fails with the same error
+type errorReader struct {
+	err error
+}
+
+func (e errorReader) Read([]byte) (int, error) {
+	return 0, e.err
+}
@@ -11,6 +11,12 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+func TestNewResponse(t *testing.T) {
+	res := &http.Response{StatusCode: 200}
+
+	assert.Equal(t, &Response{response: res}, NewResponse(res))
+}
+
 func TestResponse_UnmarshalJson(t *testing.T) {
 	var tests = []struct {
 		name      string
@@ -48,7 +54,7 @@ func TestResponse_UnmarshalJson(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			res := response{
+			res := Response{
 				response: tt.input,
 			}
 
@@ -59,6 +65,62 @@ func TestResponse_UnmarshalJson(t *testing.T) {
 		})
 	}
 }
+func TestResponse_Decode(t *testing.T) {
+	var tests = []struct {
+		name      string
+		input     *http.Response
+		want      interface{}
+		wantError bool
+	}{
+		{
+			"json",
+			&http.Response{
+				Header: http.Header{"Content-Type": {"application/json; charset=utf-8"}},
+				Body:   io.NopCloser(strings.NewReader(`{"foo":"bar"}`)),
+			},
+			map[string]interface{}{"foo": "bar"},
+			false,
+		},
+		{
+			"json suffix",
+			&http.Response{
+				Header: http.Header{"Content-Type": {"application/problem+json"}},
+				Body:   io.NopCloser(strings.NewReader(`{"foo":"bar"}`)),
+			},
+			map[string]interface{}{"foo": "bar"},
+			false,
+		},
+		{
+			"without content type",
+			&http.Response{
+				Body: io.NopCloser(strings.NewReader(`{"foo":"bar"}`)),
+			},
+			map[string]interface{}{"foo": "bar"},
+			false,
+		},
+		{
+			"unsupported content type",
+			&http.Response{
+				Header: http.Header{"Content-Type": {"application/msgpack"}},
+				Body:   io.NopCloser(strings.NewReader(`{"foo":"bar"}`)),
+			},
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := NewResponse(tt.input)
+
+			var got interface{}
+			err := res.Decode(&got)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantError, err != nil)
+		})
+	}
+}
+
 func TestResponse_UnmarshalXml(t *testing.T) {
 	type data struct {
 		XMLName xml.Name `xml:"foo"`
@@ -98,7 +160,7 @@ func TestResponse_UnmarshalXml(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			res := response{
+			res := Response{
 				response: tt.input,
 			}
 
@@ -134,7 +196,7 @@ func TestResponse_StatusCode(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			res := response{
+			res := Response{
 				response: tt.input,
 			}
 			got := res.StatusCode()
@@ -167,7 +229,7 @@ func TestResponse_Ok(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			res := response{
+			res := Response{
 				response: tt.input,
 			}
 			got := res.Ok()
@@ -200,7 +262,7 @@ func TestResponse_Created(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			res := response{
+			res := Response{
 				response: tt.input,
 			}
 			got := res.Created()
@@ -233,7 +295,7 @@ func TestResponse_NoContent(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			res := response{
+			res := Response{
 				response: tt.input,
 			}
 			got := res.NoContent()
@@ -266,7 +328,7 @@ func TestResponse_BadRequest(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			res := response{
+			res := Response{
 				response: tt.input,
 			}
 			got := res.BadRequest()
@@ -299,7 +361,7 @@ func TestResponse_NotFound(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			res := response{
+			res := Response{
 				response: tt.input,
 			}
 			got := res.NotFound()
@@ -325,7 +387,7 @@ func TestResponse_Debug(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			res := response{
+			res := Response{
 				response: tt.input,
 			}
 
@@ -334,6 +396,203 @@ func TestResponse_Debug(t *testing.T) {
 	}
 }
 
+func TestResponse_Bytes(t *testing.T) {
+	var tests = []struct {
+		name      string
+		input     *Response
+		want      string
+		wantError bool
+	}{
+		{
+			"no body",
+			&Response{response: &http.Response{}},
+			"",
+			false,
+		},
+		{
+			"body",
+			&Response{response: &http.Response{Body: io.NopCloser(strings.NewReader(`{"foo":"bar"}`))}},
+			`{"foo":"bar"}`,
+			false,
+		},
+		{
+			"within the limit",
+			&Response{response: &http.Response{Body: io.NopCloser(strings.NewReader(`{"foo":"bar"}`))}, maxBytes: 13},
+			`{"foo":"bar"}`,
+			false,
+		},
+		{
+			"over the limit",
+			&Response{response: &http.Response{Body: io.NopCloser(strings.NewReader(`{"foo":"bar"}`))}, maxBytes: 4},
+			`{"fo`,
+			true,
+		},
+		{
+			"declared length over the limit",
+			&Response{
+				response: &http.Response{ContentLength: 13, Body: io.NopCloser(strings.NewReader(`{"foo":"bar"}`))},
+				maxBytes: 4,
+			},
+			"",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 2; i++ {
+				got, err := tt.input.Bytes()
+				assert.Equal(t, tt.want, string(got))
+				assert.Equal(t, tt.wantError, err != nil)
+			}
+		})
+	}
+}
+
+func TestResponse_RepeatedReads(t *testing.T) {
+	body := &closeRecorder{Reader: strings.NewReader(`{"foo":"bar"}`)}
+	res := NewResponse(&http.Response{Body: body})
+
+	assert.Equal(t, `{"foo":"bar"}`, string(res.Debug()))
+
+	var got map[string]string
+	assert.Nil(t, res.UnmarshalJson(&got))
+	assert.Equal(t, map[string]string{"foo": "bar"}, got)
+
+	s, err := res.String()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"foo":"bar"}`, s)
+
+	b, err := io.ReadAll(res.Get().Body)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"foo":"bar"}`, string(b))
+
+	b, err = io.ReadAll(res.Body())
+	assert.Nil(t, err)
+	assert.Equal(t, `{"foo":"bar"}`, string(b))
+
+	assert.True(t, body.closed)
+}
+
+func TestResponse_MaxBytes(t *testing.T) {
+	var tests = []struct {
+		name      string
+		input     *Response
+		wantError *BodyTooLargeError
+	}{
+		{
+			"buffered",
+			&Response{response: &http.Response{ContentLength: -1, Body: io.NopCloser(strings.NewReader(`{"foo":"bar"}`))}, maxBytes: 4},
+			&BodyTooLargeError{Limit: 4, ContentLength: -1},
+		},
+		{
+			"buffered with declared length",
+			&Response{response: &http.Response{ContentLength: 13, Body: io.NopCloser(strings.NewReader(`{"foo":"bar"}`))}, maxBytes: 4},
+			&BodyTooLargeError{Limit: 4, ContentLength: 13},
+		},
+		{
+			"streamed",
+			&Response{response: &http.Response{ContentLength: -1, Body: io.NopCloser(strings.NewReader(`{"foo":"bar"}`))}, maxBytes: 4, stream: true},
+			&BodyTooLargeError{Limit: 4, ContentLength: -1},
+		},
+		{
+			"streamed with declared length",
+			&Response{response: &http.Response{ContentLength: 13, Body: io.NopCloser(strings.NewReader(`{"foo":"bar"}`))}, maxBytes: 4, stream: true},
+			&BodyTooLargeError{Limit: 4, ContentLength: 13},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]string
+			err := tt.input.UnmarshalJson(&got)
+
+			var target *BodyTooLargeError
+			if assert.True(t, errors.As(err, &target)) {
+				assert.Equal(t, tt.wantError, target)
+			}
+			assert.True(t, errors.Is(err, ErrBodyTooLarge))
+			assert.Nil(t, got)
+		})
+	}
+}
+
+func TestResponse_Stream(t *testing.T) {
+	body := &closeRecorder{Reader: strings.NewReader(`{"foo":"bar"}`)}
+	res := &Response{response: &http.Response{Body: body}, maxBytes: 13, stream: true}
+
+	var got map[string]string
+	assert.Nil(t, res.UnmarshalJson(&got))
+	assert.Equal(t, map[string]string{"foo": "bar"}, got)
+	assert.True(t, body.closed)
+
+	b, err := res.Bytes()
+	assert.Nil(t, err)
+	assert.Empty(t, b)
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestResponse_Problem(t *testing.T) {
+	var tests = []struct {
+		name      string
+		input     *http.Response
+		want      *Problem
+		wantError bool
+	}{
+		{
+			"not a problem",
+			&http.Response{
+				Header: http.Header{"Content-Type": {"application/json"}},
+				Body:   io.NopCloser(strings.NewReader(`{"title":"Bad"}`)),
+			},
+			nil,
+			false,
+		},
+		{
+			"problem",
+			&http.Response{
+				Header: http.Header{"Content-Type": {"application/problem+json"}},
+				Body:   io.NopCloser(strings.NewReader(`{"type":"https://example.com/probs/bad","title":"Bad"}`)),
+			},
+			&Problem{
+				Type:  "https://example.com/probs/bad",
+				Title: "Bad",
+			},
+			false,
+		},
+		{
+			"invalid problem",
+			&http.Response{
+				Header: http.Header{"Content-Type": {"application/problem+json"}},
+				Body:   io.NopCloser(strings.NewReader(`oops`)),
+			},
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Response{
+				response: tt.input,
+			}
+
+			got, err := res.Problem()
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantError, err != nil)
+		})
+	}
+}
+
 func TestResponse_Get(t *testing.T) {
 	var tests = []struct {
 		name  string
@@ -353,7 +612,7 @@ func TestResponse_Get(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			res := response{
+			res := Response{
 				response: tt.input,
 			}
 			got := res.Get()
@@ -0,0 +1,310 @@
+package hc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type (
+	// Backoff computes the delay to wait before the next attempt, given the attempt that just failed (starting from 1)
+	// and the previous delay
+	Backoff func(attempt int, prev time.Duration) time.Duration
+
+	// RetryPredicate decides if a request should be retried given the outcome of the last attempt
+	RetryPredicate func(res *http.Response, err error) bool
+)
+
+type attemptKey struct{}
+
+// Attempt returns the number of the attempt the request belongs to, starting from 1, useful for the middlewares
+// placed after the retry policy
+func Attempt(req *http.Request) int {
+	if v, ok := req.Context().Value(attemptKey{}).(int); ok {
+		return v
+	}
+
+	return 1
+}
+
+const (
+	defaultBackoffBase  = 100 * time.Millisecond
+	defaultBackoffLimit = 5 * time.Second
+)
+
+// RetryPolicy defines how failed requests are retried, use Retry to create it
+type RetryPolicy struct {
+	maxAttempts   int
+	maxElapsed    time.Duration
+	maxRetryAfter time.Duration
+	backoff       Backoff
+	predicates    []RetryPredicate
+}
+
+// Retry creates a retry policy, by default it performs up to 3 attempts with an exponential backoff and retries the
+// 429 status code, plus transport errors and the 502, 503 and 504 status codes of idempotent methods only
+func Retry() *RetryPolicy {
+	return &RetryPolicy{
+		maxAttempts: 3,
+	}
+}
+
+// MaxAttempts sets the maximum number of attempts, including the first one
+func (p *RetryPolicy) MaxAttempts(v int) *RetryPolicy {
+	p.maxAttempts = v
+	return p
+}
+
+// MaxElapsedTime sets the total time budget for all the attempts, no new attempt is made if waiting for it would
+// exceed the budget
+func (p *RetryPolicy) MaxElapsedTime(v time.Duration) *RetryPolicy {
+	p.maxElapsed = v
+	return p
+}
+
+// MaxRetryAfter caps the delay requested by the Retry-After header, 5 seconds by default
+func (p *RetryPolicy) MaxRetryAfter(v time.Duration) *RetryPolicy {
+	p.maxRetryAfter = v
+	return p
+}
+
+// Backoff sets the backoff used to compute the delay between attempts
+func (p *RetryPolicy) Backoff(v Backoff) *RetryPolicy {
+	p.backoff = v
+	return p
+}
+
+// RetryIf sets the predicates used to decide if a request should be retried, it's enough for one of them to match
+func (p *RetryPolicy) RetryIf(v ...RetryPredicate) *RetryPolicy {
+	p.predicates = v
+	return p
+}
+
+// ConstantBackoff waits always the same amount of time between attempts
+func ConstantBackoff(d time.Duration) Backoff {
+	return func(int, time.Duration) time.Duration {
+		return d
+	}
+}
+
+// ExponentialBackoff doubles the delay at every attempt starting from base, up to limit
+func ExponentialBackoff(base, limit time.Duration) Backoff {
+	return func(attempt int, _ time.Duration) time.Duration {
+		d := base
+		for i := 1; i < attempt; i++ {
+			d *= 2
+			// a negative delay means that doubling it overflowed
+			if d < 0 || d >= limit {
+				return limit
+			}
+		}
+
+		if d > limit {
+			return limit
+		}
+
+		return d
+	}
+}
+
+// DecorrelatedJitterBackoff picks a random delay between base and three times the previous delay, up to limit
+func DecorrelatedJitterBackoff(base, limit time.Duration) Backoff {
+	return func(_ int, prev time.Duration) time.Duration {
+		if prev < base {
+			prev = base
+		}
+
+		d := base
+		if n := int64(prev*3 - base); n > 0 {
+			d += time.Duration(rand.Int63n(n))
+		}
+
+		if d > limit {
+			return limit
+		}
+
+		return d
+	}
+}
+
+// RetryOnStatus retries the responses with one of the given status codes
+func RetryOnStatus(codes ...int) RetryPredicate {
+	return func(res *http.Response, _ error) bool {
+		if res == nil {
+			return false
+		}
+
+		for _, c := range codes {
+			if res.StatusCode == c {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// RetryOnTransportError retries the requests that failed without a response, except for canceled contexts
+func RetryOnTransportError() RetryPredicate {
+	return func(_ *http.Response, err error) bool {
+		return err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+}
+
+// idempotent tells if a request can be sent more than once without side effects, as defined by RFC 9110
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+func (p *RetryPolicy) shouldRetry(req *http.Request, res *http.Response, err error) bool {
+	predicates := p.predicates
+	if len(predicates) == 0 {
+		// the server may have processed a request that failed or got a gateway error, so only a 429 is safe to retry
+		// for every method
+		predicates = []RetryPredicate{RetryOnStatus(http.StatusTooManyRequests)}
+		if idempotent(req.Method) {
+			predicates = append(predicates,
+				RetryOnTransportError(),
+				RetryOnStatus(http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout),
+			)
+		}
+	}
+
+	for _, fn := range predicates {
+		if fn(res, err) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *RetryPolicy) delay(attempt int, prev time.Duration, res *http.Response) time.Duration {
+	if d, ok := retryAfter(res); ok {
+		limit := p.maxRetryAfter
+		if limit <= 0 {
+			limit = defaultBackoffLimit
+		}
+		if d > limit {
+			return limit
+		}
+		return d
+	}
+
+	backoff := p.backoff
+	if backoff == nil {
+		backoff = ExponentialBackoff(defaultBackoffBase, defaultBackoffLimit)
+	}
+
+	return backoff(attempt, prev)
+}
+
+// Middleware returns the policy as a middleware, so that it can be placed anywhere in a custom chain
+func (p *RetryPolicy) Middleware() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return p.do(next, req)
+		})
+	}
+}
+
+// do sends the request through the client until it succeeds, the predicates don't match anymore or the attempts
+// and time budgets are exhausted
+func (p *RetryPolicy) do(client Doer, req *http.Request) (*http.Response, error) {
+	// streamed bodies are never buffered, so they are sent once
+	if !rewindable(req) {
+		return client.Do(req)
+	}
+
+	ctx := req.Context()
+	start := time.Now()
+
+	var delay time.Duration
+	for attempt := 1; ; attempt++ {
+		// every attempt gets its own copy, so that what the middlewares change doesn't leak into the next one
+		next := req
+		if p.maxAttempts > 1 {
+			next = req.Clone(context.WithValue(ctx, attemptKey{}, attempt))
+			if attempt > 1 && req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				next.Body = body
+			}
+		}
+
+		res, err := client.Do(next)
+		if attempt >= p.maxAttempts || ctx.Err() != nil || !p.shouldRetry(req, res, err) {
+			return res, err
+		}
+
+		delay = p.delay(attempt, delay, res)
+		if p.maxElapsed > 0 && time.Since(start)+delay > p.maxElapsed {
+			return res, err
+		}
+
+		discard(res)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// rewindable tells if the request body can be sent again, ie. if it's empty or it can be re-created with GetBody
+func rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// retryAfter parses the Retry-After header of 429 and 503 responses, both in seconds and http date formats
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	if res == nil || (res.StatusCode != http.StatusTooManyRequests && res.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+
+	v := res.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if s, err := strconv.Atoi(v); err == nil {
+		if s < 0 {
+			return 0, false
+		}
+		return time.Duration(s) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
+
+// discard drains and closes the body of a response that won't be returned, so that the connection can be reused
+func discard(res *http.Response) {
+	if res == nil || res.Body == nil {
+		return
+	}
+
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	res.Body.Close()
+}
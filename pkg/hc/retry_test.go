@@ -0,0 +1,292 @@
+package hc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacoz/go-http-client/pkg/hc/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	var tests = []struct {
+		name    string
+		attempt int
+		want    time.Duration
+	}{
+		{"first attempt", 1, 100 * time.Millisecond},
+		{"second attempt", 2, 200 * time.Millisecond},
+		{"third attempt", 3, 400 * time.Millisecond},
+		{"capped", 10, time.Second},
+		{"overflowing", 100, time.Second},
+	}
+
+	backoff := ExponentialBackoff(100*time.Millisecond, time.Second)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, backoff(tt.attempt, 0))
+		})
+	}
+
+	// a zero base never grows, it doesn't jump to the limit
+	assert.Equal(t, time.Duration(0), ExponentialBackoff(0, time.Second)(3, 0))
+}
+
+func TestConstantBackoff(t *testing.T) {
+	backoff := ConstantBackoff(time.Second)
+
+	assert.Equal(t, time.Second, backoff(1, 0))
+	assert.Equal(t, time.Second, backoff(5, 3*time.Second))
+}
+
+func TestDecorrelatedJitterBackoff(t *testing.T) {
+	backoff := DecorrelatedJitterBackoff(100*time.Millisecond, time.Second)
+
+	prev := time.Duration(0)
+	for i := 1; i < 20; i++ {
+		d := backoff(i, prev)
+		assert.GreaterOrEqual(t, d, 100*time.Millisecond)
+		assert.LessOrEqual(t, d, time.Second)
+		prev = d
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	var tests = []struct {
+		name   string
+		input  *http.Response
+		want   time.Duration
+		wantOk bool
+	}{
+		{
+			"no response",
+			nil,
+			0,
+			false,
+		},
+		{
+			"ignored status",
+			&http.Response{StatusCode: 500, Header: http.Header{"Retry-After": {"2"}}},
+			0,
+			false,
+		},
+		{
+			"seconds",
+			&http.Response{StatusCode: 429, Header: http.Header{"Retry-After": {"2"}}},
+			2 * time.Second,
+			true,
+		},
+		{
+			"date in the past",
+			&http.Response{StatusCode: 503, Header: http.Header{"Retry-After": {"Wed, 21 Oct 2015 07:28:00 GMT"}}},
+			0,
+			true,
+		},
+		{
+			"invalid",
+			&http.Response{StatusCode: 503, Header: http.Header{"Retry-After": {"soon"}}},
+			0,
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := retryAfter(tt.input)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOk, ok)
+		})
+	}
+}
+
+func TestRetryPolicy_Do(t *testing.T) {
+	var tests = []struct {
+		name       string
+		method     string
+		policy     *RetryPolicy
+		results    []*http.Response
+		errors     []error
+		wantStatus int
+		wantError  error
+	}{
+		{
+			"should not retry a successful response",
+			http.MethodGet,
+			Retry().Backoff(ConstantBackoff(0)),
+			[]*http.Response{{StatusCode: 200}},
+			[]error{nil},
+			200,
+			nil,
+		},
+		{
+			"should retry until success",
+			http.MethodGet,
+			Retry().Backoff(ConstantBackoff(0)),
+			[]*http.Response{{StatusCode: 503}, nil, {StatusCode: 200}},
+			[]error{nil, errors.New("foo"), nil},
+			200,
+			nil,
+		},
+		{
+			"should stop after max attempts",
+			http.MethodGet,
+			Retry().MaxAttempts(2).Backoff(ConstantBackoff(0)),
+			[]*http.Response{{StatusCode: 502}, {StatusCode: 502}},
+			[]error{nil, nil},
+			502,
+			nil,
+		},
+		{
+			"should use custom predicates",
+			http.MethodGet,
+			Retry().Backoff(ConstantBackoff(0)).RetryIf(RetryOnStatus(500)),
+			[]*http.Response{{StatusCode: 500}, {StatusCode: 503}},
+			[]error{nil, nil},
+			503,
+			nil,
+		},
+		{
+			"should stop when the time budget is exhausted",
+			http.MethodGet,
+			Retry().Backoff(ConstantBackoff(time.Hour)).MaxElapsedTime(time.Second),
+			[]*http.Response{{StatusCode: 503}},
+			[]error{nil},
+			503,
+			nil,
+		},
+		{
+			"should cap retry after",
+			http.MethodGet,
+			Retry().Backoff(ConstantBackoff(0)).MaxRetryAfter(time.Millisecond),
+			[]*http.Response{{StatusCode: 503, Header: http.Header{"Retry-After": {"3600"}}}, {StatusCode: 200}},
+			[]error{nil, nil},
+			200,
+			nil,
+		},
+		{
+			"should not retry a gateway error of a non idempotent method",
+			http.MethodPost,
+			Retry().Backoff(ConstantBackoff(0)),
+			[]*http.Response{{StatusCode: 503}},
+			[]error{nil},
+			503,
+			nil,
+		},
+		{
+			"should retry a too many requests of a non idempotent method",
+			http.MethodPost,
+			Retry().Backoff(ConstantBackoff(0)),
+			[]*http.Response{{StatusCode: 429}, {StatusCode: 200}},
+			[]error{nil, nil},
+			200,
+			nil,
+		},
+		{
+			"should use custom predicates for a non idempotent method",
+			http.MethodPost,
+			Retry().Backoff(ConstantBackoff(0)).RetryIf(RetryOnStatus(503)),
+			[]*http.Response{{StatusCode: 503}, {StatusCode: 200}},
+			[]error{nil, nil},
+			200,
+			nil,
+		},
+		{
+			"should honor retry after",
+			http.MethodGet,
+			Retry().Backoff(ConstantBackoff(time.Hour)),
+			[]*http.Response{{StatusCode: 429, Header: http.Header{"Retry-After": {"0"}}}, {StatusCode: 200}},
+			[]error{nil, nil},
+			200,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goHttpClientMock := mocks.NewGoHttpClient(t)
+			for i := range tt.results {
+				goHttpClientMock.On("Do", mock.Anything).Return(tt.results[i], tt.errors[i]).Once()
+			}
+
+			req, _ := http.NewRequestWithContext(context.Background(), tt.method, "https://example.com", nil)
+			got, err := tt.policy.do(goHttpClientMock, req)
+
+			assert.Equal(t, tt.wantError, err)
+			if assert.NotNil(t, got) {
+				assert.Equal(t, tt.wantStatus, got.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Do_RewindsBody(t *testing.T) {
+	var bodies []string
+	readBody := func(args mock.Arguments) {
+		b, _ := io.ReadAll(args.Get(0).(*http.Request).Body)
+		bodies = append(bodies, string(b))
+	}
+
+	goHttpClientMock := mocks.NewGoHttpClient(t)
+	goHttpClientMock.On("Do", mock.Anything).Run(readBody).Return(&http.Response{StatusCode: 503}, nil).Once()
+	goHttpClientMock.On("Do", mock.Anything).Run(readBody).Return(&http.Response{StatusCode: 200}, nil).Once()
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodPut, "https://example.com", strings.NewReader(`{"foo":"bar"}`))
+	got, err := Retry().Backoff(ConstantBackoff(0)).do(goHttpClientMock, req)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 200, got.StatusCode)
+	assert.Equal(t, []string{`{"foo":"bar"}`, `{"foo":"bar"}`}, bodies)
+}
+
+func TestRetryPolicy_Do_StreamedBody(t *testing.T) {
+	goHttpClientMock := mocks.NewGoHttpClient(t)
+	goHttpClientMock.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		b, _ := io.ReadAll(req.Body)
+		return string(b) == `{"foo":"bar"}`
+	})).Return(&http.Response{StatusCode: 503}, nil).Once()
+
+	// a body which can't be re-created isn't buffered, so it's sent only once
+	body := io.MultiReader(strings.NewReader(`{"foo":`), strings.NewReader(`"bar"}`))
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodPut, "https://example.com", body)
+	got, err := Retry().Backoff(ConstantBackoff(0)).do(goHttpClientMock, req)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 503, got.StatusCode)
+}
+
+func TestRetryPolicy_Do_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	goHttpClientMock := mocks.NewGoHttpClient(t)
+	goHttpClientMock.On("Do", mock.Anything).Run(func(mock.Arguments) {
+		cancel()
+	}).Return(&http.Response{StatusCode: 503}, nil).Once()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com", nil)
+	got, err := Retry().Backoff(ConstantBackoff(time.Hour)).do(goHttpClientMock, req)
+
+	assert.Equal(t, 503, got.StatusCode)
+	assert.Nil(t, err)
+}
+
+func TestDefaultClient_Retry(t *testing.T) {
+	ctx := context.Background()
+
+	goHttpClientMock := mocks.NewGoHttpClient(t)
+	goHttpClientMock.On("Do", mock.Anything).Return(&http.Response{StatusCode: 503}, nil).Once()
+	goHttpClientMock.On("Do", mock.Anything).Return(&http.Response{StatusCode: 200}, nil).Once()
+
+	c := newClient(Opts().BaseUrl("https://example.com").WithRetry(Retry().MaxAttempts(1)))
+	c.client = goHttpClientMock
+
+	got, err := c.Get(ctx, "/foo", nil, Req().WithRetry(Retry().Backoff(ConstantBackoff(0))))
+
+	assert.Nil(t, err)
+	assert.True(t, got.Ok())
+}
@@ -0,0 +1,176 @@
+package hc
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timings is the breakdown of the duration of a request, the phases refer to the last attempt. DNS, Connect and
+// TLSHandshake are zero when a connection is reused.
+type Timings struct {
+	DNS          time.Duration
+	Connect      time.Duration
+	TLSHandshake time.Duration
+
+	// TimeToFirstByte is the time between writing the request and receiving the first byte of the response
+	TimeToFirstByte time.Duration
+
+	// ContentTransfer is the time spent reading the body, zero until the body is read to the end or closed
+	ContentTransfer time.Duration
+
+	// Total is the duration of the whole call, including the retries and the body transfer once it's done
+	Total time.Duration
+
+	ConnectionReused bool
+}
+
+// timingsRecorder collects the timings through an httptrace.ClientTrace, the hooks can be called concurrently
+type timingsRecorder struct {
+	mu sync.Mutex
+
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	received     time.Time
+	done         time.Time
+	reused       bool
+}
+
+func newTimingsRecorder() *timingsRecorder {
+	return &timingsRecorder{start: time.Now()}
+}
+
+// withTrace attaches the recorder to the context, composing it with the traces already there
+func (t *timingsRecorder) withTrace(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GetConn: func(string) {
+			t.set(t.reset)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.set(func() { t.reused = info.Reused })
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.set(func() { t.dnsStart = time.Now() })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.set(func() { t.dnsDone = time.Now() })
+		},
+		ConnectStart: func(string, string) {
+			t.set(func() {
+				if t.connectStart.IsZero() {
+					t.connectStart = time.Now()
+				}
+			})
+		},
+		ConnectDone: func(_, _ string, err error) {
+			t.set(func() {
+				if err == nil {
+					t.connectDone = time.Now()
+				}
+			})
+		},
+		TLSHandshakeStart: func() {
+			t.set(func() { t.tlsStart = time.Now() })
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.set(func() { t.tlsDone = time.Now() })
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.set(func() { t.wroteRequest = time.Now() })
+		},
+		GotFirstResponseByte: func() {
+			t.set(func() { t.firstByte = time.Now() })
+		},
+	})
+}
+
+// reset discards the phases of the previous attempt when a new one starts
+func (t *timingsRecorder) reset() {
+	t.dnsStart, t.dnsDone = time.Time{}, time.Time{}
+	t.connectStart, t.connectDone = time.Time{}, time.Time{}
+	t.tlsStart, t.tlsDone = time.Time{}, time.Time{}
+	t.wroteRequest, t.firstByte = time.Time{}, time.Time{}
+	t.reused = false
+}
+
+func (t *timingsRecorder) set(fn func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	fn()
+}
+
+// receive records when the response is returned to the caller
+func (t *timingsRecorder) receive() {
+	t.set(func() { t.received = time.Now() })
+}
+
+// finish records the end of the body transfer, only the first call counts
+func (t *timingsRecorder) finish() {
+	t.set(func() {
+		if t.done.IsZero() {
+			t.done = time.Now()
+		}
+	})
+}
+
+func (t *timingsRecorder) timings() Timings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	res := Timings{
+		DNS:              between(t.dnsStart, t.dnsDone),
+		Connect:          between(t.connectStart, t.connectDone),
+		TLSHandshake:     between(t.tlsStart, t.tlsDone),
+		TimeToFirstByte:  between(t.wroteRequest, t.firstByte),
+		ContentTransfer:  between(t.firstByte, t.done),
+		ConnectionReused: t.reused,
+	}
+
+	if !t.done.IsZero() {
+		res.Total = t.done.Sub(t.start)
+	} else {
+		res.Total = between(t.start, t.received)
+	}
+
+	return res
+}
+
+// between returns the time between two events, zero if one of them didn't happen
+func between(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() {
+		return 0
+	}
+
+	return end.Sub(start)
+}
+
+// timedBody records the end of the body transfer when the body is read to the end or closed
+type timedBody struct {
+	io.ReadCloser
+	recorder *timingsRecorder
+}
+
+func (b *timedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.recorder.finish()
+	}
+
+	return n, err
+}
+
+func (b *timedBody) Close() error {
+	b.recorder.finish()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,65 @@
+package hc
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultClient_Timings(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("foo"))
+		w.(http.Flusher).Flush()
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("bar"))
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	c := New(Opts().BaseUrl(server.URL).WithHttpClient(server.Client()).CollectTimings())
+
+	got, err := c.Get(context.Background(), "/", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "foobar", string(got.Debug()))
+
+	timings := got.Timings()
+	assert.False(t, timings.ConnectionReused)
+	assert.Greater(t, timings.Connect, time.Duration(0))
+	assert.Greater(t, timings.TLSHandshake, time.Duration(0))
+	assert.GreaterOrEqual(t, timings.TimeToFirstByte, 20*time.Millisecond)
+	assert.GreaterOrEqual(t, timings.ContentTransfer, 20*time.Millisecond)
+	assert.GreaterOrEqual(t, timings.Total, timings.TimeToFirstByte+timings.ContentTransfer)
+
+	got, err = c.Get(context.Background(), "/", nil)
+	assert.Nil(t, err)
+
+	timings = got.Timings()
+	assert.True(t, timings.ConnectionReused)
+	assert.Equal(t, time.Duration(0), timings.Connect)
+	assert.Equal(t, time.Duration(0), timings.TLSHandshake)
+	assert.Equal(t, time.Duration(0), timings.ContentTransfer)
+	assert.GreaterOrEqual(t, timings.Total, timings.TimeToFirstByte)
+}
+
+func TestDefaultClient_Timings_Request(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	c := New(Opts().BaseUrl(server.URL))
+
+	got, err := c.Get(context.Background(), "/", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, Timings{}, got.Timings())
+
+	got, err = c.Get(context.Background(), "/", nil, Req().CollectTimings())
+	assert.Nil(t, err)
+	assert.Greater(t, got.Timings().Total, time.Duration(0))
+}
@@ -0,0 +1,132 @@
+package hc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Tracer starts the spans of the requests, a span is started for every request and a child one for every attempt.
+// See the hcotel package for an OpenTelemetry adapter.
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attr) (context.Context, Span)
+}
+
+// Span is the unit of work of a request or of one of its attempts
+type Span interface {
+	SetAttributes(attrs ...Attr)
+
+	// End finishes the span, err is the transport error or a status error for 4xx and 5xx responses
+	End(err error)
+}
+
+// Propagator injects the trace context of ctx into the headers of a request, eg. traceparent, tracestate and baggage
+type Propagator interface {
+	Inject(ctx context.Context, h http.Header)
+}
+
+// traceHeaders are the W3C Trace Context and Baggage headers
+var traceHeaders = []string{"Traceparent", "Tracestate", "Baggage"}
+
+type traceHeadersKey struct{}
+
+// ContextWithTraceHeaders stores the trace context headers of an incoming request, so that TraceContextPropagator
+// forwards them to the requests made with the returned context
+func ContextWithTraceHeaders(ctx context.Context, h http.Header) context.Context {
+	v := http.Header{}
+	for _, k := range traceHeaders {
+		if values := h.Values(k); len(values) > 0 {
+			v[k] = append([]string(nil), values...)
+		}
+	}
+
+	return context.WithValue(ctx, traceHeadersKey{}, v)
+}
+
+// TraceContextPropagator forwards the traceparent, tracestate and baggage headers stored with ContextWithTraceHeaders,
+// it doesn't need any tracing library
+type TraceContextPropagator struct{}
+
+func (TraceContextPropagator) Inject(ctx context.Context, h http.Header) {
+	v, _ := ctx.Value(traceHeadersKey{}).(http.Header)
+	for k, values := range v {
+		if h.Get(k) == "" {
+			h[k] = append([]string(nil), values...)
+		}
+	}
+}
+
+// spanName follows the OpenTelemetry conventions for http client spans, eg. GET /users/{id}
+func spanName(req *http.Request) string {
+	if v, ok := endpointTemplate(req); ok {
+		return req.Method + " " + v
+	}
+
+	return req.Method
+}
+
+// startSpan starts the span of a request, the attributes follow the OpenTelemetry semantic conventions. The route is
+// set only for templated endpoints, since a raw path has a high cardinality.
+func startSpan(t Tracer, req *http.Request, attrs ...Attr) (context.Context, Span) {
+	base := []Attr{
+		{"http.request.method", req.Method},
+		{"url.full", spanUrl(req.URL)},
+		{"server.address", req.URL.Hostname()},
+	}
+	if v, ok := endpointTemplate(req); ok {
+		base = append(base, Attr{"http.route", v})
+	}
+
+	return t.Start(req.Context(), spanName(req), append(base, attrs...)...)
+}
+
+// spanUrl hides the password and the values of the query parameters, which can carry api keys and tokens
+func spanUrl(u *url.URL) string {
+	q := u.Query()
+	for k := range q {
+		q[k] = []string{redacted}
+	}
+
+	res := *u
+	res.RawQuery = q.Encode()
+
+	return res.Redacted()
+}
+
+// endSpan records the outcome of the request on the span and ends it
+func endSpan(span Span, res *http.Response, err error) {
+	if err == nil && res != nil {
+		span.SetAttributes(Attr{"http.response.status_code", res.StatusCode})
+		if res.StatusCode >= 400 {
+			err = fmt.Errorf("hc: %s", http.StatusText(res.StatusCode))
+		}
+	}
+
+	span.End(err)
+}
+
+// tracingMiddleware starts a span for every attempt and injects its trace context into the headers
+func tracingMiddleware(t Tracer, p Propagator) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			var span Span
+			if t != nil {
+				var ctx context.Context
+				ctx, span = startSpan(t, req, Attr{"http.request.resend_count", Attempt(req) - 1})
+				req = req.WithContext(ctx)
+			}
+
+			if p != nil {
+				p.Inject(req.Context(), req.Header)
+			}
+
+			res, err := next.Do(req)
+			if span != nil {
+				endSpan(span, res, err)
+			}
+
+			return res, err
+		})
+	}
+}
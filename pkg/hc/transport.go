@@ -0,0 +1,116 @@
+package hc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type transportOptions struct {
+	tlsConfig             *tls.Config
+	certificates          []tls.Certificate
+	rootCAs               *x509.CertPool
+	minTLSVersion         uint16
+	proxy                 func(*http.Request) (*url.URL, error)
+	maxIdleConns          int
+	maxIdleConnsPerHost   int
+	maxConnsPerHost       int
+	idleConnTimeout       time.Duration
+	dialTimeout           time.Duration
+	tlsHandshakeTimeout   time.Duration
+	responseHeaderTimeout time.Duration
+	roundTripper          http.RoundTripper
+}
+
+// isZero tells if no transport option has been set, in which case the default transport is used
+func (t *transportOptions) isZero() bool {
+	return t.tlsConfig == nil &&
+		len(t.certificates) == 0 &&
+		t.rootCAs == nil &&
+		t.minTLSVersion == 0 &&
+		t.proxy == nil &&
+		t.maxIdleConns == 0 &&
+		t.maxIdleConnsPerHost == 0 &&
+		t.maxConnsPerHost == 0 &&
+		t.idleConnTimeout == 0 &&
+		t.dialTimeout == 0 &&
+		t.tlsHandshakeTimeout == 0 &&
+		t.responseHeaderTimeout == 0
+}
+
+// newHttpClient builds the client used to send the requests, unless one has been provided
+func (o *Options) newHttpClient() *http.Client {
+	if o.httpClient != nil {
+		return o.httpClient
+	}
+
+	return &http.Client{
+		Timeout:   o.timeout,
+		Transport: o.transport.newRoundTripper(),
+	}
+}
+
+// newRoundTripper returns the custom round tripper if set, otherwise a copy of the default transport with the
+// options applied. It returns nil when nothing is configured, so that the default transport is shared.
+func (t *transportOptions) newRoundTripper() http.RoundTripper {
+	if t.roundTripper != nil {
+		return t.roundTripper
+	}
+
+	if t.isZero() {
+		return nil
+	}
+
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+
+	if t.tlsConfig != nil || len(t.certificates) > 0 || t.rootCAs != nil || t.minTLSVersion != 0 {
+		cfg := &tls.Config{}
+		if t.tlsConfig != nil {
+			cfg = t.tlsConfig.Clone()
+		}
+		cfg.Certificates = append(cfg.Certificates, t.certificates...)
+		if t.rootCAs != nil {
+			cfg.RootCAs = t.rootCAs
+		}
+		if t.minTLSVersion != 0 {
+			cfg.MinVersion = t.minTLSVersion
+		}
+		tr.TLSClientConfig = cfg
+	}
+
+	if t.proxy != nil {
+		tr.Proxy = t.proxy
+	}
+
+	if t.maxIdleConns != 0 {
+		tr.MaxIdleConns = t.maxIdleConns
+	}
+	if t.maxIdleConnsPerHost != 0 {
+		tr.MaxIdleConnsPerHost = t.maxIdleConnsPerHost
+	}
+	if t.maxConnsPerHost != 0 {
+		tr.MaxConnsPerHost = t.maxConnsPerHost
+	}
+	if t.idleConnTimeout != 0 {
+		tr.IdleConnTimeout = t.idleConnTimeout
+	}
+
+	if t.dialTimeout != 0 {
+		tr.DialContext = (&net.Dialer{
+			Timeout:   t.dialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+	}
+
+	if t.tlsHandshakeTimeout != 0 {
+		tr.TLSHandshakeTimeout = t.tlsHandshakeTimeout
+	}
+	if t.responseHeaderTimeout != 0 {
+		tr.ResponseHeaderTimeout = t.responseHeaderTimeout
+	}
+
+	return tr
+}
@@ -0,0 +1,136 @@
+package hc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptions_NewHttpClient(t *testing.T) {
+	custom := &http.Client{}
+	roundTripper := &http.Transport{}
+	pool := x509.NewCertPool()
+	proxy, _ := url.Parse("http://proxy.example.com:3128")
+
+	var tests = []struct {
+		name   string
+		input  *Options
+		assert func(t *testing.T, got *http.Client)
+	}{
+		{
+			"defaults",
+			Opts(),
+			func(t *testing.T, got *http.Client) {
+				assert.Equal(t, 10*time.Second, got.Timeout)
+				assert.Nil(t, got.Transport)
+			},
+		},
+		{
+			"total timeout",
+			Opts().TotalTimeout(500 * time.Millisecond),
+			func(t *testing.T, got *http.Client) {
+				assert.Equal(t, 500*time.Millisecond, got.Timeout)
+			},
+		},
+		{
+			"custom http client",
+			Opts().WithHttpClient(custom).MaxIdleConns(5),
+			func(t *testing.T, got *http.Client) {
+				assert.Same(t, custom, got)
+			},
+		},
+		{
+			"custom round tripper",
+			Opts().WithTransport(roundTripper).MaxIdleConns(5),
+			func(t *testing.T, got *http.Client) {
+				assert.Same(t, roundTripper, got.Transport)
+			},
+		},
+		{
+			"tls",
+			Opts().
+				WithTLSConfig(&tls.Config{ServerName: "example.com"}).
+				WithClientCertificate(tls.Certificate{}).
+				WithRootCAs(pool).
+				MinTLSVersion(tls.VersionTLS12),
+			func(t *testing.T, got *http.Client) {
+				cfg := got.Transport.(*http.Transport).TLSClientConfig
+
+				assert.Equal(t, "example.com", cfg.ServerName)
+				assert.Len(t, cfg.Certificates, 1)
+				assert.Same(t, pool, cfg.RootCAs)
+				assert.Equal(t, uint16(tls.VersionTLS12), cfg.MinVersion)
+			},
+		},
+		{
+			"proxy",
+			Opts().WithProxy(proxy),
+			func(t *testing.T, got *http.Client) {
+				req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+				u, err := got.Transport.(*http.Transport).Proxy(req)
+
+				assert.Nil(t, err)
+				assert.Equal(t, proxy, u)
+			},
+		},
+		{
+			"connection pool",
+			Opts().
+				MaxIdleConns(50).
+				MaxIdleConnsPerHost(10).
+				MaxConnsPerHost(20).
+				IdleConnTimeout(time.Minute).
+				DialTimeout(time.Second).
+				TLSHandshakeTimeout(2 * time.Second).
+				ResponseHeaderTimeout(3 * time.Second),
+			func(t *testing.T, got *http.Client) {
+				tr := got.Transport.(*http.Transport)
+
+				assert.Equal(t, 50, tr.MaxIdleConns)
+				assert.Equal(t, 10, tr.MaxIdleConnsPerHost)
+				assert.Equal(t, 20, tr.MaxConnsPerHost)
+				assert.Equal(t, time.Minute, tr.IdleConnTimeout)
+				assert.NotNil(t, tr.DialContext)
+				assert.Equal(t, 2*time.Second, tr.TLSHandshakeTimeout)
+				assert.Equal(t, 3*time.Second, tr.ResponseHeaderTimeout)
+				assert.NotSame(t, http.DefaultTransport, tr)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.assert(t, tt.input.newHttpClient())
+		})
+	}
+}
+
+func TestDefaultClient_RootCAs(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+
+	c := New(Opts().BaseUrl(server.URL).WithRootCAs(pool).MinTLSVersion(tls.VersionTLS12))
+	got, err := c.Get(context.Background(), "/", nil)
+
+	assert.Nil(t, err)
+	assert.True(t, got.NoContent())
+
+	_, err = New(Opts().BaseUrl(server.URL)).Get(context.Background(), "/", nil)
+	assert.NotNil(t, err)
+}
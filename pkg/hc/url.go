@@ -0,0 +1,86 @@
+package hc
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// parseBaseUrl validates the base url of the client, an empty base url is allowed and requires absolute endpoints
+func parseBaseUrl(v string) (*url.URL, error) {
+	if v == "" {
+		return nil, nil
+	}
+
+	u, err := url.Parse(v)
+	if err != nil {
+		return nil, fmt.Errorf("hc: invalid base url %q: %w", v, err)
+	}
+
+	if !u.IsAbs() || u.Host == "" {
+		return nil, fmt.Errorf("hc: invalid base url %q: %w", v, errors.New("scheme and host are required"))
+	}
+
+	return u, nil
+}
+
+// resolveUrl resolves the endpoint against the base url.
+//
+// Absolute endpoints, with an http or https scheme and a host, are used as they are and protocol relative ones
+// (//host/path) take the scheme of the base url. Everything else is relative, eg. users:batch is a path and not a url
+// with the users scheme. Relative endpoints, with or without the leading slash, are appended to the path of the base
+// url and resolved as in RFC 3986, so that dot segments work as expected. The query of the endpoint is merged into the
+// one of the base url.
+func resolveUrl(base *url.URL, endpoint string) (*url.URL, error) {
+	ref, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("hc: invalid endpoint %q: %w", endpoint, err)
+	}
+
+	if base == nil || absolute(ref) {
+		return ref, nil
+	}
+
+	if ref.Scheme != "" {
+		// the colon belongs to the first path segment, the dot segment keeps it from being parsed as a scheme again
+		if ref, err = url.Parse("./" + endpoint); err != nil {
+			return nil, fmt.Errorf("hc: invalid endpoint %q: %w", endpoint, err)
+		}
+	}
+
+	if ref.Host != "" {
+		ref.Scheme = base.Scheme
+		return ref, nil
+	}
+
+	u := *base
+	if ref.Path != "" {
+		dir := *base
+		if !strings.HasSuffix(dir.Path, "/") {
+			dir.Path += "/"
+			if dir.RawPath != "" {
+				dir.RawPath += "/"
+			}
+		}
+
+		rel := *ref
+		rel.Path = strings.TrimPrefix(rel.Path, "/")
+		rel.RawPath = strings.TrimPrefix(rel.RawPath, "/")
+		rel.RawQuery = ""
+
+		u = *dir.ResolveReference(&rel)
+	}
+
+	query := base.Query()
+	mergeValues(query, ref.Query())
+	u.RawQuery = query.Encode()
+	u.Fragment = ref.Fragment
+
+	return &u, nil
+}
+
+// absolute tells if the endpoint is a full http url, which replaces the base url
+func absolute(u *url.URL) bool {
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,120 @@
+package hc
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/jacoz/go-http-client/pkg/hc/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestParseBaseUrl(t *testing.T) {
+	var tests = []struct {
+		name      string
+		input     string
+		want      string
+		wantError string
+	}{
+		{
+			"empty",
+			"",
+			"",
+			"",
+		},
+		{
+			"valid",
+			"https://api.example.com/v1?key=secret",
+			"https://api.example.com/v1?key=secret",
+			"",
+		},
+		{
+			"without scheme",
+			"api.example.com",
+			"",
+			`hc: invalid base url "api.example.com": scheme and host are required`,
+		},
+		{
+			"malformed",
+			"https://api.example.com:port",
+			"",
+			`hc: invalid base url "https://api.example.com:port": parse "https://api.example.com:port": invalid port ":port" after host`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseBaseUrl(tt.input)
+
+			if tt.wantError != "" {
+				assert.EqualError(t, err, tt.wantError)
+				assert.Nil(t, got)
+				return
+			}
+
+			assert.Nil(t, err)
+			if tt.want == "" {
+				assert.Nil(t, got)
+			} else {
+				assert.Equal(t, tt.want, got.String())
+			}
+		})
+	}
+}
+
+func TestResolveUrl(t *testing.T) {
+	var tests = []struct {
+		name     string
+		base     string
+		endpoint string
+		want     string
+	}{
+		{"without base url", "", "https://example.com/users", "https://example.com/users"},
+		{"leading slash", "https://api.x.com", "/users", "https://api.x.com/users"},
+		{"no leading slash", "https://api.x.com", "users", "https://api.x.com/users"},
+		{"both slashes", "https://api.x.com/v1/", "/users", "https://api.x.com/v1/users"},
+		{"no slashes", "https://api.x.com/v1", "users", "https://api.x.com/v1/users"},
+		{"empty endpoint", "https://api.x.com/v1", "", "https://api.x.com/v1"},
+		{"dot segments", "https://api.x.com/v1", "../v2/users", "https://api.x.com/v2/users"},
+		{"escaped path", "https://api.x.com/v1", "/files/a%2Fb", "https://api.x.com/v1/files/a%2Fb"},
+		{"absolute endpoint", "https://api.x.com/v1", "http://other.com/users", "http://other.com/users"},
+		{"colon in the first segment", "https://api.x.com/v1", "users:batch", "https://api.x.com/v1/users:batch"},
+		{"colon with a query", "https://api.x.com/v1?key=secret", "users:batch?id=1", "https://api.x.com/v1/users:batch?id=1&key=secret"},
+		{"http scheme without host", "https://api.x.com/v1", "http:users", "https://api.x.com/v1/http:users"},
+		{"protocol relative endpoint", "https://api.x.com/v1", "//other.com/users", "https://other.com/users"},
+		{"base url query", "https://api.x.com/v1?key=secret", "/users", "https://api.x.com/v1/users?key=secret"},
+		{"merged queries", "https://api.x.com/v1?key=secret&page=1", "/users?page=2&id=1", "https://api.x.com/v1/users?id=1&key=secret&page=2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, _ := parseBaseUrl(tt.base)
+			got, err := resolveUrl(base, tt.endpoint)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+
+	_, err := resolveUrl(nil, "http://[::1")
+	assert.NotNil(t, err)
+}
+
+func TestDefaultClient_Url(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := New(Opts().BaseUrl("api.example.com")).Get(ctx, "/users", nil)
+	assert.EqualError(t, err, `hc: invalid base url "api.example.com": scheme and host are required`)
+
+	goHttpClientMock := mocks.NewGoHttpClient(t)
+	goHttpClientMock.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.String() == "https://api.x.com/v1/users?default=query&id=1&id=2&key=secret&page=3"
+	})).Return(&http.Response{}, nil)
+
+	c := newClient(Opts().BaseUrl("https://api.x.com/v1?key=secret").WithDefaultQuery(Q{"default": "query", "page": "1"}))
+	c.client = goHttpClientMock
+
+	_, err = c.Get(ctx, "users?page=2&id=1&id=2", &Q{"page": "3"})
+	assert.Nil(t, err)
+}